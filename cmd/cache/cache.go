@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/diskcache"
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the OVH API responses cache",
+		Long:  "Manage the OVH API responses cache\n\nThe catalog and availabilities responses are cached on disk between commands,\nuse --no-cache to disable the cache or --refresh to ignore the cached responses.",
+	}
+
+	clearCmd = &cobra.Command{
+		Use:     "clear",
+		Short:   "Remove all cached responses",
+		Example: `  kimsufi-notifier cache clear`,
+		RunE:    clearRunner,
+	}
+
+	infoCmd = &cobra.Command{
+		Use:     "info",
+		Short:   "Show cache information",
+		Example: `  kimsufi-notifier cache info`,
+		RunE:    infoRunner,
+	}
+)
+
+// init registers all subcommands
+func init() {
+	Cmd.AddCommand(clearCmd)
+	Cmd.AddCommand(infoCmd)
+}
+
+// clearRunner is the main function for the cache clear command
+func clearRunner(cmd *cobra.Command, args []string) error {
+	c, err := newCache()
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.Clear()
+	if err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+
+	fmt.Printf("removed %d cached responses from %s\n", removed, c.Dir)
+
+	return nil
+}
+
+// infoRunner is the main function for the cache info command
+func infoRunner(cmd *cobra.Command, args []string) error {
+	c, err := newCache()
+	if err != nil {
+		return err
+	}
+
+	info, err := c.Info()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	fmt.Printf("directory: %s\n", info.Dir)
+	fmt.Printf("entries:   %d (%d expired)\n", info.Entries, info.Expired)
+	fmt.Printf("size:      %d bytes\n", info.Size)
+	fmt.Println("ttl:")
+	for _, ttl := range c.TTLs {
+		fmt.Printf("  %s: %s\n", ttl.Path, ttl.Duration)
+	}
+
+	return nil
+}
+
+// newCache returns the cache in the default directory.
+func newCache() (*diskcache.Cache, error) {
+	dir, err := diskcache.DefaultDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	return diskcache.New(dir), nil
+}
@@ -0,0 +1,229 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/order"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/orderflow"
+)
+
+// autoOrderRunner polls the availability of the checks at the given interval,
+// and orders the server configurations as soon as they become available, until maxOrders is reached.
+func autoOrderRunner(cmd *cobra.Command, k *kimsufi.Service, catalog *kimsuficatalog.Catalog, checks []config.Check) error {
+	// Flag validation
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+	if maxOrders <= 0 {
+		return fmt.Errorf("--max-orders must be greater than 0")
+	}
+
+	req := orderflow.OrderRequest{
+		Endpoint:   cmd.Flag(flag.OVHAPIEndpointFlagName).Value.String(),
+		Subsidiary: cmd.Flag(flag.CountryFlagName).Value.String(),
+		Quantity:   kimsufiorder.QuantityDefault,
+		PriceConfig: kimsufiorder.EcoItemPriceConfig{
+			Duration:    kimsufiorder.PriceDuration,
+			PricingMode: kimsufiorder.PricingMode,
+		},
+		AutoPay: autoPay,
+		DryRun:  dryRun,
+	}
+
+	if !dryRun {
+		// Read OVH API credentials from environment
+		var err error
+		req.Credentials, err = credentialsFlags.Credentials()
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	// Stop polling on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a := newAutoOrderer(k, catalog, checks, req, maxOrders)
+	a.executor.OnEvent = order.PrintEvent
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done := a.poll(ctx)
+		if ctx.Err() != nil {
+			fmt.Printf("> %d order(s) placed\n", a.orders)
+			return nil
+		}
+		if done {
+			fmt.Printf("> %d order(s) placed, stopping\n", a.orders)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Printf("> %d order(s) placed\n", a.orders)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// configurationKey identifies a server configuration in a datacenter.
+type configurationKey struct {
+	planCode   string
+	memory     string
+	storage    string
+	datacenter string
+}
+
+// autoOrderer orders the server configurations of the checks as soon as they become available.
+// A configuration is ordered once while it stays available, the ones available on the first poll included,
+// failed orders are retried on the next poll.
+type autoOrderer struct {
+	k         *kimsufi.Service
+	catalog   *kimsuficatalog.Catalog
+	checks    []config.Check
+	executor  *orderflow.Executor
+	req       orderflow.OrderRequest
+	maxOrders int
+
+	// ordered holds the configurations ordered and still available, for each check plan code.
+	ordered map[string]map[configurationKey]bool
+	orders  int
+}
+
+// newAutoOrderer returns an autoOrderer placing up to maxOrders orders with req.
+func newAutoOrderer(k *kimsufi.Service, catalog *kimsuficatalog.Catalog, checks []config.Check, req orderflow.OrderRequest, maxOrders int) *autoOrderer {
+	return &autoOrderer{
+		k:         k,
+		catalog:   catalog,
+		checks:    checks,
+		executor:  orderflow.NewExecutor(k, nil),
+		req:       req,
+		maxOrders: maxOrders,
+		ordered:   make(map[string]map[configurationKey]bool),
+	}
+}
+
+// poll gets the availabilities of the checks once and orders the available configurations not ordered yet.
+// It returns true when maxOrders is reached, it stops early when ctx is canceled.
+func (a *autoOrderer) poll(ctx context.Context) bool {
+	for i, check := range a.checks {
+		for _, planCode := range check.PlanCodes {
+			key := fmt.Sprintf("%d/%s", i, planCode)
+
+			availabilities, err := a.k.GetAvailabilitiesWithContext(ctx, check.Datacenters, planCode, check.Options)
+			if err != nil {
+				if ctx.Err() != nil {
+					return false
+				}
+				if !kimsufi.IsAvailabilityNotFoundError(err) {
+					// Keep the ordered configurations, their availability is unknown.
+					log.Errorf("failed to get availabilities: %v", err)
+					continue
+				}
+
+				// Nothing is available
+				availabilities = &kimsufiavailability.Availabilities{}
+			}
+
+			// Configurations no longer available are forgotten, to order them again when they come back.
+			previous := a.ordered[key]
+			ordered := make(map[configurationKey]bool)
+			a.ordered[key] = ordered
+
+			for _, v := range *availabilities {
+				for _, dc := range v.GetAvailableDatacenters() {
+					c := configurationKey{planCode: v.PlanCode, memory: v.Memory, storage: v.Storage, datacenter: dc.Datacenter}
+					if previous[c] {
+						ordered[c] = true
+						continue
+					}
+
+					// Evaluate the where expression against each datacenter, to only order from the matching ones.
+					if !matchWhere(a.catalog, v.PlanCode, v.Memory, v.Storage, kimsufiavailability.Datacenters{dc}) {
+						continue
+					}
+
+					if !a.order(ctx, c) {
+						if ctx.Err() != nil {
+							return false
+						}
+						continue
+					}
+
+					ordered[c] = true
+					a.orders++
+					if a.orders >= a.maxOrders {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// order orders the configuration and returns true when the order is placed, or the cart created in dry-run mode.
+func (a *autoOrderer) order(ctx context.Context, c configurationKey) bool {
+	fmt.Printf("> %s %s memory=%s storage=%s datacenter=%s available, ordering\n", time.Now().Format(time.RFC3339), c.planCode, c.memory, c.storage, c.datacenter)
+
+	req := a.req
+	req.PlanCode = c.planCode
+	req.Datacenters = []string{c.datacenter}
+	req.Options = orderOptions(a.catalog, c.planCode, c.memory, c.storage)
+
+	resp, err := a.executor.Execute(ctx, req)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Errorf("failed to order %s: %v", c.planCode, err)
+		}
+		return false
+	}
+
+	// Nothing was ordered, the server is no longer available.
+	return resp != nil || req.DryRun
+}
+
+// orderOptions returns the item options matching exactly the memory and storage of an availability,
+// e.g. memory=ram-32g-noecc-2133-24ska01.
+// Families not found in the plan addons are left to the order defaults.
+func orderOptions(catalog *kimsuficatalog.Catalog, planCode, memory, storage string) []string {
+	plan := catalog.GetPlan(planCode)
+	if plan == nil {
+		return nil
+	}
+
+	// Iterate in a fixed order, to always build the same options.
+	var options []string
+	for _, o := range []struct {
+		family      string
+		genericName string
+	}{
+		{family: kimsuficatalog.AddonMemory, genericName: memory},
+		{family: kimsuficatalog.AddonStorage, genericName: storage},
+	} {
+		addon := kimsufi.FindAddon(plan, o.family, o.genericName)
+		if addon != "" {
+			options = append(options, o.family+"="+addon)
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,78 @@
+package check
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/orderflow"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/ovhfake"
+)
+
+// failFirstCart fails the first cart creation request.
+type failFirstCart struct {
+	failed bool
+}
+
+func (t *failFirstCart) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.failed && req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/order/cart") {
+		t.failed = true
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"message":"temporarily unavailable"}`)),
+			Request:    req,
+		}, nil
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAutoOrdererPoll(t *testing.T) {
+	server := httptest.NewServer(ovhfake.New(ovhfake.DefaultScenario()))
+	defer server.Close()
+
+	k, err := kimsufi.NewServiceWithTransport(server.URL, &failFirstCart{}, log.StandardLogger(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	catalog, err := k.ListServersWithContext(ctx, "FR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := orderflow.OrderRequest{
+		Endpoint:   server.URL,
+		Subsidiary: "FR",
+		Quantity:   kimsufiorder.QuantityDefault,
+		DryRun:     true,
+	}
+	checks := []config.Check{{PlanCodes: []string{"24ska01"}}}
+
+	a := newAutoOrderer(k, catalog, checks, req, 3)
+
+	// 24ska01 stays available in gra and rbx, the order failing on the first poll is retried on the second one,
+	// then nothing is ordered again while it stays available.
+	var orders []int
+	for range 3 {
+		if a.poll(ctx) {
+			t.Fatal("unexpected max orders reached")
+		}
+		orders = append(orders, a.orders)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 2}, orders); diff != "" {
+		t.Errorf("orders mismatch (-want +got):\n%s", diff)
+	}
+}
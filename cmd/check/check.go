@@ -3,17 +3,25 @@ package check
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/notify"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/expr"
 	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
 	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
 	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/hardware"
 	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
 )
 
 var (
@@ -22,8 +30,13 @@ var (
 		Short: "Check server availability",
 		Long:  "Check OVH Eco (including Kimsufi) server availability\n\ndatacenters are the available datacenters for this plan",
 		Example: `  kimsufi-notifier check --plan-code 24ska01
-  kimsufi-notifier check --plan-code 24ska01 --datacenters gra,rbx`,
-		RunE: runner,
+  kimsufi-notifier check --plan-code 24ska01 --datacenters gra,rbx
+  kimsufi-notifier check --all --config config.yaml
+  kimsufi-notifier check --plan-code 24ska01 --endpoint all
+  kimsufi-notifier check --plan-code 24ska01 --where 'ram >= 32 && dc in ["gra","rbx"]'
+  kimsufi-notifier check --plan-code 24ska01 --datacenters gra,rbx --auto-order --max-orders 1`,
+		RunE:        runner,
+		Annotations: map[string]string{flag.ConfigAnnotation: "true", flag.OutputAnnotation: "true"},
 	}
 
 	// Flags variables
@@ -31,38 +44,149 @@ var (
 	options     map[string]string
 	planCode    string
 	humanLevel  int
+	where       string
 
+	all             bool
 	listDatacenters bool
 	listOptions     bool
+
+	notifyFlags notify.Flags
+
+	// whereExpr is the compiled where flag expression, nil when not set.
+	whereExpr *expr.Expr
+
+	autoOrder        bool
+	autoPay          bool
+	dryRun           bool
+	interval         time.Duration
+	maxOrders        int
+	retryCart        bool
+	credentialsFlags flag.OVHCredentials
 )
 
+// Result represents the availability of a server configuration,
+// it is the schema of the json, jsonl, yaml and csv outputs.
+type Result struct {
+	// Endpoint is the OVH endpoint of the result, only set with --endpoint all.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// Country is the OVH subsidiary of the catalog, only set with --endpoint all.
+	Country string `json:"country,omitempty" yaml:"country,omitempty"`
+	// Check is the name of the configuration check, only set with --all.
+	Check       string  `json:"check,omitempty" yaml:"check,omitempty"`
+	PlanCode    string  `json:"planCode" yaml:"planCode"`
+	Category    string  `json:"category" yaml:"category"`
+	InvoiceName string  `json:"invoiceName" yaml:"invoiceName"`
+	Price       float64 `json:"price" yaml:"price"`
+	Currency    string  `json:"currency" yaml:"currency"`
+	Memory      string  `json:"memory" yaml:"memory"`
+	Storage     string  `json:"storage" yaml:"storage"`
+	// Status is either available or unavailable.
+	Status string `json:"status" yaml:"status"`
+	// Datacenters holds the codes of the datacenters where the server is available.
+	Datacenters []string `json:"datacenters" yaml:"datacenters"`
+	// DatacenterNames holds the full names of the datacenters where the server is available.
+	DatacenterNames []string `json:"datacenterNames" yaml:"datacenterNames"`
+}
+
 // init registers all flags
 func init() {
 	flag.BindPlanCodeFlag(Cmd, &planCode)
 	flag.BindDatacentersFlag(Cmd, &datacenters)
 	flag.BindHumanFlag(Cmd, &humanLevel)
+	flag.BindWhereFlag(Cmd, &where)
+	notify.Bind(Cmd, &notifyFlags)
 
+	Cmd.PersistentFlags().BoolVar(&all, "all", false, "evaluate all the checks from the configuration file")
 	Cmd.PersistentFlags().BoolVar(&listDatacenters, "list-datacenters", false, "list available datacenters")
 	Cmd.PersistentFlags().BoolVar(&listOptions, "list-options", false, "list available item options")
 	Cmd.PersistentFlags().StringToStringVarP(&options, "option", "o", nil, "options to filter on, comma separated list of key=value, see --list-options for available options (e.g. memory=ram-64g-noecc-2133)")
+
+	Cmd.PersistentFlags().BoolVar(&autoOrder, "auto-order", false, "poll availability and order servers as soon as they become available")
+	Cmd.PersistentFlags().BoolVar(&autoPay, "auto-pay", false, "automatically pay the orders, only with --auto-order")
+	Cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "only create a cart and do not submit the orders, only with --auto-order")
+	Cmd.PersistentFlags().DurationVar(&interval, "interval", time.Minute, "polling interval, only with --auto-order")
+	Cmd.PersistentFlags().IntVar(&maxOrders, "max-orders", 1, "stop after this number of orders, only with --auto-order")
+	flag.BindRetryCartFlag(Cmd, &retryCart)
+	flag.BindOVHCredentialsFlags(Cmd, &credentialsFlags)
 }
 
 // runner is the main function for the check command
 func runner(cmd *cobra.Command, args []string) error {
-	// Initialize kimsufi service
-	endpoint := cmd.Flag(flag.OVHAPIEndpointFlagName).Value.String()
-	k, err := kimsufi.NewService(endpoint, log.StandardLogger(), nil)
+	// Flag validation
+	if all && planCode != "" {
+		return fmt.Errorf("--all and --%s are mutually exclusive", flag.PlanCodeFlagName)
+	}
+	if !all && planCode == "" {
+		return fmt.Errorf("--%s is required", flag.PlanCodeFlagName)
+	}
+	if all && (listDatacenters || listOptions) {
+		return fmt.Errorf("--list-datacenters and --list-options require --%s", flag.PlanCodeFlagName)
+	}
+	if autoOrder && (listDatacenters || listOptions) {
+		return fmt.Errorf("--auto-order and --list-datacenters or --list-options are mutually exclusive")
+	}
+
+	checks := []config.Check{
+		{
+			PlanCodes:   []string{planCode},
+			Datacenters: datacenters,
+			Options:     options,
+		},
+	}
+	if all {
+		c := flag.Config(cmd)
+		if c == nil || len(c.Checks) == 0 {
+			return fmt.Errorf("--all requires checks in the configuration file")
+		}
+		checks = c.Checks
+	}
+
+	var err error
+	whereExpr, err = flag.CompileWhere(where)
+	if err != nil {
+		return err
+	}
+
+	printer, err := output.NewPrinter(cmd.Flag(flag.OutputFlagName).Value.String(), cmd.Flag(flag.TemplateFileFlagName).Value.String())
 	if err != nil {
 		return fmt.Errorf("error: %w", err)
 	}
 
-	// Flag validation
-	if planCode == "" {
-		return fmt.Errorf("--%s is required", flag.PlanCodeFlagName)
+	if (autoOrder || listDatacenters || listOptions) && printer.Format != output.FormatTable {
+		return fmt.Errorf("--auto-order, --list-datacenters and --list-options only support the %s output", output.FormatTable)
+	}
+
+	notifiers, err := notifyFlags.NewRegistry()
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	if flag.IsAllEndpoints(cmd) {
+		if autoOrder || listDatacenters || listOptions {
+			return fmt.Errorf("--auto-order, --list-datacenters and --list-options are not supported with --%s %s", flag.OVHAPIEndpointFlagName, flag.OVHAPIEndpointAll)
+		}
+
+		catalogs, results, events, err := checkAllEndpoints(cmd, checks)
+		if err != nil {
+			return err
+		}
+
+		return report(cmd, printer, notifiers, catalogs, results, events)
+	}
+
+	// Initialize kimsufi service, availabilities are polled with --auto-order
+	var k *kimsufi.Service
+	if autoOrder {
+		k, err = flag.NewPollingService(cmd, interval)
+	} else {
+		k, err = flag.NewService(cmd)
+	}
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
 	}
 
 	var catalog *kimsuficatalog.Catalog
-	if humanLevel > 0 || listDatacenters || listOptions {
+	if humanLevel > 0 || listDatacenters || listOptions || notifiers.Len() > 0 || printer.Format != output.FormatTable || autoOrder || whereExpr != nil {
 		// Get the catalog to display human readable information.
 		catalog, err = k.ListServers(cmd.Flag(flag.CountryFlagName).Value.String())
 		if err != nil {
@@ -78,36 +202,173 @@ func runner(cmd *cobra.Command, args []string) error {
 		return printItemOptions(catalog, planCode)
 	}
 
-	// Check availability
-	availabilities, err := k.GetAvailabilities(datacenters, planCode, options)
-	if err != nil {
-		if kimsufi.IsAvailabilityNotFoundError(err) {
-			message := datacenterAvailableMessageFormatter(datacenters)
-			log.Printf("%s is not available in %s\n", planCode, message)
-			return nil
+	if autoOrder {
+		return autoOrderRunner(cmd, k, catalog, checks)
+	}
+
+	var (
+		events  []notifier.Event
+		results []Result
+	)
+	for _, check := range checks {
+		for _, planCode := range check.PlanCodes {
+			// Check availability
+			availabilities, err := k.GetAvailabilities(check.Datacenters, planCode, check.Options)
+			if err != nil {
+				if !kimsufi.IsAvailabilityNotFoundError(err) {
+					return fmt.Errorf("error: %w", err)
+				}
+
+				message := datacenterAvailableMessageFormatter(check.Datacenters)
+				if all {
+					log.Printf("%s: %s is not available in %s\n", check.Name, planCode, message)
+					continue
+				}
+
+				if printer.Format != output.FormatTable {
+					return printer.Print(os.Stdout, []Result{})
+				}
+
+				log.Printf("%s is not available in %s\n", planCode, message)
+				return nil
+			}
+
+			r, e := newResults(catalog, check.Name, *availabilities)
+			results = append(results, r...)
+			events = append(events, e...)
 		}
+	}
 
-		return fmt.Errorf("error: %w", err)
+	return report(cmd, printer, notifiers, map[string]*kimsuficatalog.Catalog{"": catalog}, results, events)
+}
+
+// newResults returns the results of the availabilities and the notification events of the available ones.
+// Availabilities not matching the where expression are skipped.
+// catalog is optional, see newResult, it is required with a where expression.
+func newResults(catalog *kimsuficatalog.Catalog, checkName string, availabilities kimsufiavailability.Availabilities) ([]Result, []notifier.Event) {
+	var (
+		events  []notifier.Event
+		results []Result
+	)
+	for _, v := range availabilities {
+		datacenters := v.GetAvailableDatacenters()
+		if !matchWhere(catalog, v.PlanCode, v.Memory, v.Storage, datacenters) {
+			continue
+		}
+
+		r := newResult(catalog, v.PlanCode, v.Memory, v.Storage, datacenters)
+		r.Check = checkName
+		if r.Status == kimsufiavailability.StatusAvailable {
+			events = append(events, notifier.NewEvent(catalog, v.PlanCode, v.Memory, v.Storage, datacenters))
+		}
+
+		results = append(results, r)
+	}
+
+	return results, events
+}
+
+// matchWhere returns true if the server configuration available in the datacenters matches the where expression,
+// or when no expression is set.
+func matchWhere(catalog *kimsuficatalog.Catalog, planCode, memory, storage string, datacenters kimsufiavailability.Datacenters) bool {
+	if whereExpr == nil {
+		return true
+	}
+
+	c := hardware.Lookup(catalog, planCode, memory, storage)
+	return whereExpr.Match(c.Row(datacenters))
+}
+
+// report prints the results, sends the notifications and exits with code 1 when nothing is available.
+// catalogs holds the catalog of each result endpoint, used for the human readable table.
+func report(cmd *cobra.Command, printer *output.Printer, notifiers *notifier.Registry, catalogs map[string]*kimsuficatalog.Catalog, results []Result, events []notifier.Event) error {
+	if printer.Format == output.FormatTable {
+		printTable(catalogs, results)
+	} else {
+		err := printer.Print(os.Stdout, results)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	notify.Send(cmd.Context(), notifiers, events)
+
+	if len(events) == 0 {
+		// Nothing available
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// newResult returns the availability result of a server configuration.
+// catalog is optional, when set it is used to fill in the category, invoice name, price and currency.
+func newResult(catalog *kimsuficatalog.Catalog, planCode, memory, storage string, datacenters kimsufiavailability.Datacenters) Result {
+	r := Result{
+		PlanCode:        planCode,
+		Memory:          memory,
+		Storage:         storage,
+		Status:          datacenters.Status(),
+		Datacenters:     datacenters.Codes(),
+		DatacenterNames: datacenters.ToFullNamesOrCodes(),
+	}
+
+	// Always encode empty lists instead of null.
+	if r.Datacenters == nil {
+		r.Datacenters = []string{}
+		r.DatacenterNames = []string{}
+	}
+
+	if catalog == nil {
+		return r
+	}
+
+	r.Currency = catalog.Locale.CurrencyCode
+
+	plan := catalog.GetPlan(planCode)
+	if plan != nil {
+		r.Category = plan.GetCategory()
+		r.InvoiceName = plan.InvoiceName
+		r.Price = plan.GetFirstPrice().GetPrice()
 	}
 
-	// Display the server availabilities for each options.
+	return r
+}
+
+// printTable displays the server availabilities for each options as a table.
+// catalogs holds the catalog of each result endpoint.
+func printTable(catalogs map[string]*kimsuficatalog.Catalog, results []Result) {
+	allEndpoints := slices.ContainsFunc(results, func(r Result) bool {
+		return r.Endpoint != ""
+	})
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	if allEndpoints {
+		fmt.Fprint(w, "endpoint\tcountry\t") // nolint:errcheck
+	}
+	if all {
+		fmt.Fprint(w, "check\t") // nolint:errcheck
+	}
 	fmt.Fprintln(w, "planCode\tmemory\tstorage\tstatus\tdatacenters") // nolint:errcheck
+	if allEndpoints {
+		fmt.Fprint(w, "--------\t-------\t") // nolint:errcheck
+	}
+	if all {
+		fmt.Fprint(w, "-----\t") // nolint:errcheck
+	}
 	fmt.Fprintln(w, "--------\t------\t-------\t------\t-----------") // nolint:errcheck
 
-	nothingAvailable := true
-	for _, v := range *availabilities {
+	for _, r := range results {
 		var (
-			name        = v.PlanCode
-			memory      = v.Memory
-			storage     = v.Storage
-			datacenters = v.GetAvailableDatacenters()
+			name    = r.PlanCode
+			memory  = r.Memory
+			storage = r.Storage
+			catalog = catalogs[r.Endpoint]
 		)
 
-		if humanLevel > 0 {
-			plan := catalog.GetPlan(v.PlanCode)
-			if plan != nil {
-				names := strings.Split(plan.InvoiceName, " | ")
+		if humanLevel > 0 && catalog != nil {
+			if r.InvoiceName != "" {
+				names := strings.Split(r.InvoiceName, " | ")
 				name = names[0]
 			}
 
@@ -122,27 +383,20 @@ func runner(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		var datacenterNames []string
+		datacenterNames := r.Datacenters
 		if humanLevel > 1 {
-			datacenterNames = datacenters.ToFullNamesOrCodes()
-		} else {
-			datacenterNames = datacenters.Codes()
+			datacenterNames = r.DatacenterNames
 		}
 
-		var status = datacenters.Status()
-		if status == kimsufiavailability.StatusAvailable {
-			nothingAvailable = false
+		if allEndpoints {
+			fmt.Fprintf(w, "%s\t%s\t", r.Endpoint, r.Country) // nolint:errcheck
 		}
-
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, memory, storage, status, strings.Join(datacenterNames, ", ")) // nolint:errcheck
+		if all {
+			fmt.Fprintf(w, "%s\t", r.Check) // nolint:errcheck
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, memory, storage, r.Status, strings.Join(datacenterNames, ", ")) // nolint:errcheck
 	}
 	w.Flush() // nolint:errcheck
-
-	if nothingAvailable {
-		os.Exit(1)
-	}
-
-	return nil
 }
 
 func datacenterAvailableMessageFormatter(datacenters []string) string {
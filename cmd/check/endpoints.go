@@ -0,0 +1,103 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+// endpointCheck holds the check results of an endpoint.
+type endpointCheck struct {
+	catalog *kimsuficatalog.Catalog
+	results []Result
+	events  []notifier.Event
+}
+
+// checkAllEndpoints evaluates the checks against all the OVH endpoints concurrently,
+// it returns the catalog of each endpoint, the results and the notification events.
+// Each result keeps the currency of its endpoint catalog, an endpoint failing is logged and skipped.
+func checkAllEndpoints(cmd *cobra.Command, checks []config.Check) (map[string]*kimsuficatalog.Catalog, []Result, []notifier.Event, error) {
+	m, err := flag.NewMultiService(cmd)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error: %w", err)
+	}
+
+	endpointResults := kimsufi.RunAll(cmd.Context(), m, flag.MaxConcurrentEndpoints, func(ctx context.Context, endpoint string, k *kimsufi.Service) (endpointCheck, error) {
+		return checkEndpoint(ctx, k, flag.EndpointCountry(cmd, endpoint), endpoint, checks)
+	})
+
+	var (
+		catalogs = make(map[string]*kimsuficatalog.Catalog)
+		events   []notifier.Event
+		results  []Result
+		errs     []error
+	)
+	for _, r := range endpointResults {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+
+		catalogs[r.Endpoint] = r.Value.catalog
+		results = append(results, r.Value.results...)
+		events = append(events, r.Value.events...)
+	}
+
+	if len(errs) == len(endpointResults) {
+		return nil, nil, nil, fmt.Errorf("failed to check availability on all endpoints: %w", errors.Join(errs...))
+	}
+
+	// Report the failing endpoints and keep the results of the others
+	for _, err := range errs {
+		log.Error(err)
+	}
+
+	return catalogs, results, events, nil
+}
+
+// checkEndpoint evaluates the checks against a single endpoint,
+// plans without availabilities on the endpoint are skipped.
+func checkEndpoint(ctx context.Context, k *kimsufi.Service, country, endpoint string, checks []config.Check) (endpointCheck, error) {
+	var c endpointCheck
+
+	// Get the catalog for the price and currency of the endpoint.
+	catalog, err := k.ListServersWithContext(ctx, country)
+	if err != nil {
+		return c, fmt.Errorf("failed to list servers: %w", err)
+	}
+	c.catalog = catalog
+
+	for _, check := range checks {
+		for _, planCode := range check.PlanCodes {
+			availabilities, err := k.GetAvailabilitiesWithContext(ctx, check.Datacenters, planCode, check.Options)
+			if err != nil {
+				if !kimsufi.IsAvailabilityNotFoundError(err) {
+					return c, fmt.Errorf("failed to get availabilities: %w", err)
+				}
+
+				log.Debugf("%s: %s is not available in %s\n", endpoint, planCode, datacenterAvailableMessageFormatter(check.Datacenters))
+				continue
+			}
+
+			results, events := newResults(catalog, check.Name, *availabilities)
+			for i := range results {
+				results[i].Endpoint = endpoint
+				results[i].Country = country
+			}
+
+			c.results = append(c.results, results...)
+			c.events = append(c.events, events...)
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,404 @@
+package compare
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	pkgcategory "github.com/TheoBrigitte/kimsufi-notifier/pkg/category"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:   "compare PLAN...",
+		Short: "Compare server plans",
+		Long:  "Compare OVH Eco (including Kimsufi) server plans side by side: hardware specifications, options, prices and availability per datacenter",
+		Example: `  kimsufi-notifier compare 24sk40 25skle02
+  kimsufi-notifier compare 24ska01 24sk10 --datacenters gra,rbx
+  kimsufi-notifier compare 24sk40 25skle02 --output json`,
+		Args:        cobra.MinimumNArgs(1),
+		RunE:        runner,
+		Annotations: map[string]string{flag.ConfigAnnotation: "true", flag.OutputAnnotation: "true"},
+	}
+
+	// Flags variables
+	datacenters []string
+	humanLevel  int
+)
+
+// Result represents the specifications, prices and availability of a server plan,
+// it is the schema of the json, jsonl, yaml and csv outputs.
+type Result struct {
+	PlanCode    string `json:"planCode" yaml:"planCode"`
+	Category    string `json:"category" yaml:"category"`
+	InvoiceName string `json:"invoiceName" yaml:"invoiceName"`
+	CPU         CPU    `json:"cpu" yaml:"cpu"`
+	// Memory and Storage hold the options of the plan, the first one is included in the price.
+	Memory  []Option `json:"memory" yaml:"memory"`
+	Storage []Option `json:"storage" yaml:"storage"`
+	// Bandwidth is the bandwidth in Mbit/s.
+	Bandwidth float64 `json:"bandwidth" yaml:"bandwidth"`
+	SetupFee  float64 `json:"setupFee" yaml:"setupFee"`
+	// Price is the monthly price without commitment.
+	Price       float64      `json:"price" yaml:"price"`
+	Commitments []Commitment `json:"commitments" yaml:"commitments"`
+	Currency    string       `json:"currency" yaml:"currency"`
+	// Datacenters holds the availability of the plan in each datacenter, in any configuration.
+	Datacenters []Datacenter `json:"datacenters" yaml:"datacenters"`
+}
+
+// CPU represents the processor of a server plan.
+type CPU struct {
+	Brand   string `json:"brand" yaml:"brand"`
+	Model   string `json:"model" yaml:"model"`
+	Cores   int    `json:"cores" yaml:"cores"`
+	Threads int    `json:"threads" yaml:"threads"`
+	// Frequency is the frequency in GHz.
+	Frequency float64 `json:"frequency" yaml:"frequency"`
+}
+
+// Option represents a memory or storage option of a server plan.
+type Option struct {
+	// Name is the generic name of the option (e.g. ram-32g-noecc-2133).
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Price is the monthly price added to the plan price.
+	Price float64 `json:"price" yaml:"price"`
+}
+
+// Commitment represents a price with a commitment, either degressive or paid upfront.
+type Commitment struct {
+	// Months is the commitment duration in months.
+	Months int    `json:"months" yaml:"months"`
+	Mode   string `json:"mode" yaml:"mode"`
+	// Price is the price charged every Interval months.
+	Price    float64 `json:"price" yaml:"price"`
+	Interval int     `json:"interval" yaml:"interval"`
+	// MonthlyPrice is the price per month.
+	MonthlyPrice float64 `json:"monthlyPrice" yaml:"monthlyPrice"`
+}
+
+// Datacenter represents the availability of a server plan in a datacenter.
+type Datacenter struct {
+	Datacenter string `json:"datacenter" yaml:"datacenter"`
+	Name       string `json:"name" yaml:"name"`
+	// Availability is the best availability of the plan configurations (e.g. 1H-low), or unavailable.
+	Availability string `json:"availability" yaml:"availability"`
+}
+
+// init registers all flags
+func init() {
+	flag.BindDatacentersFlag(Cmd, &datacenters)
+	flag.BindHumanFlag(Cmd, &humanLevel)
+}
+
+// runner is the main function for the compare command
+func runner(cmd *cobra.Command, args []string) error {
+	if flag.IsAllEndpoints(cmd) {
+		return fmt.Errorf("error: --%s %s is not supported by compare", flag.OVHAPIEndpointFlagName, flag.OVHAPIEndpointAll)
+	}
+
+	printer, err := output.NewPrinter(cmd.Flag(flag.OutputFlagName).Value.String(), cmd.Flag(flag.TemplateFileFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	// Initialize kimsufi service
+	k, err := flag.NewService(cmd)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	catalog, err := k.ListServersWithContext(cmd.Context(), cmd.Flag(flag.CountryFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	var results []Result
+	for _, planCode := range args {
+		plan := catalog.GetPlan(planCode)
+		if plan == nil {
+			return fmt.Errorf("plan %s not found", planCode)
+		}
+
+		availabilities, err := k.GetAvailabilitiesWithContext(cmd.Context(), datacenters, planCode, nil)
+		if err != nil && !kimsufi.IsAvailabilityNotFoundError(err) {
+			return fmt.Errorf("failed to get availabilities: %w", err)
+		}
+		if availabilities == nil {
+			availabilities = &kimsufiavailability.Availabilities{}
+		}
+
+		results = append(results, newResult(catalog, plan, *availabilities))
+	}
+
+	if printer.Format == output.FormatTable {
+		printTable(results)
+		return nil
+	}
+
+	err = printer.Print(os.Stdout, results)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	return nil
+}
+
+// newResult returns the comparison result of the plan.
+func newResult(catalog *kimsuficatalog.Catalog, plan *kimsuficatalog.Plan, availabilities kimsufiavailability.Availabilities) Result {
+	r := Result{
+		PlanCode:    plan.PlanCode,
+		Category:    plan.GetCategory(),
+		InvoiceName: plan.InvoiceName,
+		Memory:      options(catalog, plan, kimsuficatalog.AddonMemory),
+		Storage:     options(catalog, plan, kimsuficatalog.AddonStorage),
+		SetupFee:    plan.GetSetupFee().GetPrice(),
+		Price:       plan.GetFirstPrice().GetPrice(),
+		Commitments: []Commitment{},
+		Currency:    catalog.Locale.CurrencyCode,
+		Datacenters: datacentersAvailability(availabilities),
+	}
+
+	var technical kimsuficatalog.ProductBlobsTechnical
+	if product := catalog.GetProduct(plan.Product); product != nil {
+		technical = product.Blobs.Technical
+	}
+
+	cpu := technical.Server.CPU
+	number := max(cpu.Number, 1)
+	r.CPU = CPU{
+		Brand:     cpu.Brand,
+		Model:     cpu.Model,
+		Cores:     cpu.Cores * number,
+		Threads:   cpu.Threads * number,
+		Frequency: cpu.Frequency,
+	}
+
+	r.Bandwidth = technical.Bandwidth.Level
+	if f := plan.GetAddon(kimsuficatalog.AddonBandwidth); f != nil && f.Default != "" {
+		if p := catalog.GetProduct(kimsufi.AddonGenericName(f.Default)); p != nil {
+			r.Bandwidth = p.Blobs.Technical.Bandwidth.Level
+		}
+	}
+
+	for _, price := range plan.GetCommitmentPrices() {
+		r.Commitments = append(r.Commitments, Commitment{
+			Months:       price.Commitement,
+			Mode:         price.Mode,
+			Price:        price.GetPrice(),
+			Interval:     price.Interval,
+			MonthlyPrice: price.GetMonthlyPrice(),
+		})
+	}
+
+	return r
+}
+
+// options returns the options of the addon family of the plan,
+// described by their product when found in the catalog.
+func options(catalog *kimsuficatalog.Catalog, plan *kimsuficatalog.Plan, family string) []Option {
+	o := []Option{}
+
+	f := plan.GetAddon(family)
+	if f == nil {
+		return o
+	}
+
+	for _, addon := range f.Addons {
+		option := Option{
+			Name: kimsufi.AddonGenericName(addon),
+		}
+
+		if p := catalog.GetProduct(option.Name); p != nil {
+			option.Description = p.Description
+		}
+		if a := catalog.GetAddon(addon); a != nil {
+			option.Price = a.GetFirstPrice().GetPrice()
+		}
+
+		o = append(o, option)
+	}
+
+	return o
+}
+
+// datacentersAvailability returns the best availability of the configurations in each datacenter, sorted by datacenter.
+func datacentersAvailability(availabilities kimsufiavailability.Availabilities) []Datacenter {
+	var (
+		codes []string
+		best  = make(map[string]string)
+	)
+	for _, a := range availabilities {
+		for _, d := range a.Datacenters {
+			current, ok := best[d.Datacenter]
+			if !ok {
+				codes = append(codes, d.Datacenter)
+			}
+			if !ok || (!(kimsufiavailability.Datacenter{Availability: current}).IsAvailable() && d.IsAvailable()) {
+				best[d.Datacenter] = d.Availability
+			}
+		}
+	}
+
+	datacenters := []Datacenter{}
+	for _, code := range codes {
+		d := Datacenter{
+			Datacenter:   code,
+			Name:         code,
+			Availability: best[code],
+		}
+		if info := kimsufiavailability.GetDatacenterInfoByCode(code); info != nil {
+			d.Name = info.Name
+		}
+
+		datacenters = append(datacenters, d)
+	}
+
+	slices.SortFunc(datacenters, func(a, b Datacenter) int {
+		return strings.Compare(a.Datacenter, b.Datacenter)
+	})
+
+	return datacenters
+}
+
+// printTable displays the plans side by side, one column per plan.
+func printTable(results []Result) {
+	type row struct {
+		name   string
+		values func(r Result) []string
+	}
+
+	rows := []row{
+		{"name", func(r Result) []string {
+			return []string{r.InvoiceName}
+		}},
+		{"category", func(r Result) []string {
+			category := pkgcategory.GetDisplayName(r.Category)
+			if category == "" {
+				category = r.Category
+			}
+			return []string{category}
+		}},
+		{"cpu", func(r Result) []string {
+			return []string{strings.TrimSpace(r.CPU.Brand + " " + r.CPU.Model)}
+		}},
+		{"cores/threads", func(r Result) []string {
+			if r.CPU.Cores == 0 {
+				return []string{"-"}
+			}
+			return []string{fmt.Sprintf("%dc/%dt", r.CPU.Cores, r.CPU.Threads)}
+		}},
+		{"frequency", func(r Result) []string {
+			if r.CPU.Frequency == 0 {
+				return []string{"-"}
+			}
+			return []string{fmt.Sprintf("%g GHz", r.CPU.Frequency)}
+		}},
+		{"memory", func(r Result) []string {
+			return formatOptions(r.Memory, r.Currency)
+		}},
+		{"storage", func(r Result) []string {
+			return formatOptions(r.Storage, r.Currency)
+		}},
+		{"bandwidth", func(r Result) []string {
+			if r.Bandwidth == 0 {
+				return []string{"-"}
+			}
+			return []string{fmt.Sprintf("%g Mbps", r.Bandwidth)}
+		}},
+		{"setup fee", func(r Result) []string {
+			return []string{fmt.Sprintf("%.2f %s", r.SetupFee, r.Currency)}
+		}},
+		{"price", func(r Result) []string {
+			return []string{fmt.Sprintf("%.2f %s/month", r.Price, r.Currency)}
+		}},
+		{"commitment", func(r Result) []string {
+			var lines []string
+			for _, c := range r.Commitments {
+				lines = append(lines, fmt.Sprintf("%dm %s: %.2f %s/month", c.Months, c.Mode, c.MonthlyPrice, r.Currency))
+			}
+			return lines
+		}},
+		{"datacenters", func(r Result) []string {
+			var lines []string
+			for _, d := range r.Datacenters {
+				name := d.Datacenter
+				if humanLevel > 0 {
+					name = d.Name
+				}
+				lines = append(lines, fmt.Sprintf("%s: %s", name, d.Availability))
+			}
+			return lines
+		}},
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+
+	fmt.Fprint(w, "planCode") // nolint:errcheck
+	for _, r := range results {
+		fmt.Fprintf(w, "\t%s", r.PlanCode) // nolint:errcheck
+	}
+	fmt.Fprintln(w) // nolint:errcheck
+
+	fmt.Fprint(w, "--------") // nolint:errcheck
+	for _, r := range results {
+		fmt.Fprintf(w, "\t%s", strings.Repeat("-", len(r.PlanCode))) // nolint:errcheck
+	}
+	fmt.Fprintln(w) // nolint:errcheck
+
+	// Each row spans as many lines as its longest value, e.g. one line per option.
+	for _, row := range rows {
+		values := make([][]string, len(results))
+		lines := 1
+		for i, r := range results {
+			values[i] = row.values(r)
+			lines = max(lines, len(values[i]))
+		}
+
+		for line := range lines {
+			name := ""
+			if line == 0 {
+				name = row.name
+			}
+
+			fmt.Fprint(w, name) // nolint:errcheck
+			for _, v := range values {
+				value := ""
+				if line < len(v) {
+					value = v[line]
+				}
+				if line == 0 && value == "" {
+					value = "-"
+				}
+				fmt.Fprintf(w, "\t%s", value) // nolint:errcheck
+			}
+			fmt.Fprintln(w) // nolint:errcheck
+		}
+	}
+
+	w.Flush() // nolint:errcheck
+}
+
+// formatOptions returns the options with their additional price, one per line.
+func formatOptions(options []Option, currency string) []string {
+	var lines []string
+	for _, o := range options {
+		name := o.Name
+		if humanLevel > 0 && o.Description != "" {
+			name = o.Description
+		}
+		lines = append(lines, fmt.Sprintf("%s (+%.2f %s)", name, o.Price, currency))
+	}
+
+	return lines
+}
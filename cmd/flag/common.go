@@ -1,14 +1,20 @@
 package flag
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/TheoBrigitte/kimsufi-notifier/pkg/category"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/expr"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
 	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/hardware"
 )
 
 const (
@@ -23,6 +29,15 @@ const (
 	PlanCodeFlagName      = "plan-code"
 	PlanCodeFlagShortName = "p"
 	PlanCodeExample       = "24ska01"
+
+	OVHAppKeyFlagName      = "ovh-app-key"
+	OVHAppSecretFlagName   = "ovh-app-secret"
+	OVHConsumerKeyFlagName = "ovh-consumer-key"
+
+	RetryCartFlagName = "retry-cart"
+
+	WhereFlagName = "where"
+	WhereExample  = `ram >= 32 && ecc && price < 15 && dc in ["gra","rbx"]`
 )
 
 // BindCategoryFlag binds the country flag to the provided cmd and value.
@@ -49,3 +64,68 @@ func BindHumanFlag(cmd *cobra.Command, value *int) {
 func BindPlanCodeFlag(cmd *cobra.Command, value *string) {
 	cmd.PersistentFlags().StringVarP(value, PlanCodeFlagName, PlanCodeFlagShortName, "", fmt.Sprintf("plan code name (e.g. %s)", PlanCodeExample))
 }
+
+// BindRetryCartFlag binds the retry cart flag to the provided cmd and value.
+func BindRetryCartFlag(cmd *cobra.Command, value *bool) {
+	cmd.PersistentFlags().BoolVar(value, RetryCartFlagName, false, "also retry the failed cart requests, which may create duplicate carts or items (the checkout is never retried)")
+}
+
+// BindWhereFlag binds the where flag to the provided cmd and value.
+func BindWhereFlag(cmd *cobra.Command, value *string) {
+	vars := slices.Sorted(maps.Keys(hardware.RowVars))
+	cmd.PersistentFlags().StringVar(value, WhereFlagName, "", fmt.Sprintf("filter expression evaluated against each server configuration (e.g. '%s'), variables: %s", WhereExample, strings.Join(vars, ", ")))
+}
+
+// CompileWhere compiles the where flag expression, it returns nil when the expression is empty.
+// Syntax errors point at the offending column of the expression.
+func CompileWhere(expression string) (*expr.Expr, error) {
+	if expression == "" {
+		return nil, nil
+	}
+
+	e, err := expr.Compile(expression, hardware.RowVars)
+	if err != nil {
+		var syntaxErr *expr.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("invalid --%s expression: %w\n%s", WhereFlagName, err, syntaxErr.Pointer())
+		}
+		return nil, fmt.Errorf("invalid --%s expression: %w", WhereFlagName, err)
+	}
+
+	return e, nil
+}
+
+// OVHCredentials holds the names of the environment variables containing the OVH API credentials.
+type OVHCredentials struct {
+	AppKeyEnvVarName      string
+	AppSecretEnvVarName   string
+	ConsumerKeyEnvVarName string
+}
+
+// BindOVHCredentialsFlags binds the OVH API credentials flags to the provided cmd and value.
+func BindOVHCredentialsFlags(cmd *cobra.Command, value *OVHCredentials) {
+	cmd.PersistentFlags().StringVar(&value.AppKeyEnvVarName, OVHAppKeyFlagName, "OVH_APP_KEY", "environement variable name for OVH API application key")
+	cmd.PersistentFlags().StringVar(&value.AppSecretEnvVarName, OVHAppSecretFlagName, "OVH_APP_SECRET", "environement variable name for OVH API application secret")
+	cmd.PersistentFlags().StringVar(&value.ConsumerKeyEnvVarName, OVHConsumerKeyFlagName, "OVH_CONSUMER_KEY", "environement variable name for OVH API consumer key")
+}
+
+// Credentials reads the OVH API credentials from the environment.
+func (c OVHCredentials) Credentials() (kimsufi.Credentials, error) {
+	creds := kimsufi.Credentials{
+		AppKey:      os.Getenv(c.AppKeyEnvVarName),
+		AppSecret:   os.Getenv(c.AppSecretEnvVarName),
+		ConsumerKey: os.Getenv(c.ConsumerKeyEnvVarName),
+	}
+
+	if creds.AppKey == "" {
+		return creds, fmt.Errorf("%s env var is required", c.AppKeyEnvVarName)
+	}
+	if creds.AppSecret == "" {
+		return creds, fmt.Errorf("%s env var is required", c.AppSecretEnvVarName)
+	}
+	if creds.ConsumerKey == "" {
+		return creds, fmt.Errorf("%s env var is required", c.ConsumerKeyEnvVarName)
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,58 @@
+package flag
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
+)
+
+// ConfigAnnotation marks the commands using the configuration file,
+// it is only loaded for them.
+const ConfigAnnotation = "config"
+
+// configKey is the context key of the loaded configuration.
+type configKey struct{}
+
+// UsesConfig returns true when the command uses the configuration file.
+func UsesConfig(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[ConfigAnnotation]
+	return ok
+}
+
+// WithConfig returns a copy of ctx holding the loaded configuration.
+func WithConfig(ctx context.Context, c *config.Config) context.Context {
+	return context.WithValue(ctx, configKey{}, c)
+}
+
+// Config returns the configuration loaded before running the command,
+// or nil when there is none or the command does not use it.
+func Config(cmd *cobra.Command) *config.Config {
+	c, _ := cmd.Context().Value(configKey{}).(*config.Config)
+	return c
+}
+
+// LoadConfig loads the configuration file from the --config flag,
+// or from the default path when not set.
+// It returns nil when the default configuration file does not exist.
+func LoadConfig(cmd *cobra.Command) (*config.Config, error) {
+	path := cmd.Flag(ConfigFlagName).Value.String()
+	if path != "" {
+		return config.Load(path)
+	}
+
+	path, err := config.DefaultPath()
+	if err != nil {
+		return nil, nil
+	}
+
+	c, err := config.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return c, err
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
 	kimsufiregion "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/region"
 	"github.com/TheoBrigitte/kimsufi-notifier/pkg/logger"
+	pkgoutput "github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
 )
 
 const (
@@ -20,10 +21,24 @@ const (
 	OVHAPIEndpointFlagName      = "endpoint"
 	OVHAPIEndpointFlagShortName = "e"
 	OVHAPIEndpointDefault       = "ovh-eu"
+	OVHAPIEndpointAll           = "all"
 
 	CountryFlagName      = "country"
 	CountryFlagShortName = "c"
 	CountryDefault       = "FR"
+
+	ConfigFlagName = "config"
+
+	OutputFlagName       = "output"
+	TemplateFileFlagName = "template-file"
+
+	RecordFlagName = "record"
+	ReplayFlagName = "replay"
+
+	MaxAttemptsFlagName = "max-attempts"
+
+	NoCacheFlagName = "no-cache"
+	RefreshFlagName = "refresh"
 )
 
 // Bind binds the global flags to the provided cmd.
@@ -31,11 +46,14 @@ func Bind(cmd *cobra.Command) {
 	// Redefine help flag to only be a long --help flag
 	cmd.PersistentFlags().Bool("help", false, "help for "+cmd.Name())
 
+	// Configuration file
+	cmd.PersistentFlags().String(ConfigFlagName, "", "configuration file (default $XDG_CONFIG_HOME/kimsufi-notifier/config.yaml or ~/.config/kimsufi-notifier/config.yaml)")
+
 	// Log level
 	cmd.PersistentFlags().StringP(LogLevelFlagName, LogLevelFlagShortName, log.ErrorLevel.String(), fmt.Sprintf("log level (allowed values: %s)", strings.Join(logger.AllLevelsString(), ", ")))
 
 	// OVH API Endpoint
-	cmd.PersistentFlags().StringP(OVHAPIEndpointFlagName, OVHAPIEndpointFlagShortName, OVHAPIEndpointDefault, fmt.Sprintf("OVH API Endpoint (allowed values: %s)", strings.Join(kimsufi.GetOVHEndpoints(), ", ")))
+	cmd.PersistentFlags().StringP(OVHAPIEndpointFlagName, OVHAPIEndpointFlagShortName, OVHAPIEndpointDefault, fmt.Sprintf("OVH API Endpoint (allowed values: %s), list and check also accept %s to query all the endpoints", strings.Join(kimsufi.GetOVHEndpoints(), ", "), OVHAPIEndpointAll))
 
 	// Country
 	// Display all countries per endpoint
@@ -51,4 +69,21 @@ func Bind(cmd *cobra.Command) {
 	}
 
 	cmd.PersistentFlags().StringP(CountryFlagName, CountryFlagShortName, CountryDefault, fmt.Sprintf("country code, known values per endpoints:\n%s", output.String()))
+
+	// Output format
+	cmd.PersistentFlags().String(OutputFlagName, string(pkgoutput.FormatTable), fmt.Sprintf("output format (allowed values: %s), use template=<template> to render each result with a Go template (e.g. template='{{ .PlanCode }} {{ join .Datacenters \",\" }}')", strings.Join(pkgoutput.FormatNames(), ", ")))
+	cmd.PersistentFlags().String(TemplateFileFlagName, "", "file containing a Go template to render each result with, overrides --"+OutputFlagName)
+
+	// OVH API requests retries
+	cmd.PersistentFlags().Int(MaxAttemptsFlagName, kimsufi.DefaultRetryPolicy.MaxAttempts, "maximum number of attempts of the failed OVH API requests (rate limited or temporarily unavailable), 1 disables retries")
+
+	// OVH API responses disk cache
+	cmd.PersistentFlags().Bool(NoCacheFlagName, false, "do not use the OVH API responses cache")
+	cmd.PersistentFlags().Bool(RefreshFlagName, false, "ignore the cached OVH API responses and refresh the cache")
+
+	// OVH API interactions cassette, for bug reports and tests
+	cmd.PersistentFlags().String(RecordFlagName, "", "record the OVH API interactions to a cassette file, credentials are redacted")
+	cmd.PersistentFlags().String(ReplayFlagName, "", "replay the OVH API interactions from a cassette file, without the network")
+	cmd.PersistentFlags().MarkHidden(RecordFlagName) // nolint:errcheck
+	cmd.PersistentFlags().MarkHidden(ReplayFlagName) // nolint:errcheck
 }
@@ -0,0 +1,33 @@
+package flag
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	pkgoutput "github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
+)
+
+// OutputAnnotation marks the commands supporting the --output and --template-file flags,
+// the other commands only print tables.
+const OutputAnnotation = "output"
+
+// UsesOutput returns true when the command supports the output flags.
+func UsesOutput(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[OutputAnnotation]
+	return ok
+}
+
+// ValidateOutput returns an error when the output flags are set
+// on a command which does not support them.
+func ValidateOutput(cmd *cobra.Command) error {
+	if UsesOutput(cmd) {
+		return nil
+	}
+
+	if cmd.Flag(OutputFlagName).Value.String() != string(pkgoutput.FormatTable) || cmd.Flag(TemplateFileFlagName).Value.String() != "" {
+		return fmt.Errorf("--%s and --%s are not supported by the %s command", OutputFlagName, TemplateFileFlagName, cmd.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package flag
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/cassette"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/diskcache"
+	kimsufiregion "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/region"
+)
+
+// NewService returns the kimsufi service for the --endpoint flag,
+// recording or replaying the API interactions with --record and --replay.
+// Otherwise the responses are cached on disk, unless --no-cache is set.
+// Failed requests are retried up to --max-attempts times.
+func NewService(cmd *cobra.Command) (*kimsufi.Service, error) {
+	return newService(cmd, diskcache.DefaultTTLs)
+}
+
+// NewPollingService returns the kimsufi service for the commands polling availabilities every interval,
+// configured the same way as NewService except that availabilities are cached on disk for less than interval.
+func NewPollingService(cmd *cobra.Command, interval time.Duration) (*kimsufi.Service, error) {
+	return newService(cmd, diskcache.PollingTTLs(interval))
+}
+
+// newService returns the kimsufi service for the --endpoint flag, caching the responses on disk with ttls.
+func newService(cmd *cobra.Command, ttls []diskcache.TTL) (*kimsufi.Service, error) {
+	endpoint := cmd.Flag(OVHAPIEndpointFlagName).Value.String()
+	if endpoint == OVHAPIEndpointAll {
+		return nil, fmt.Errorf("--%s %s is not supported by this command", OVHAPIEndpointFlagName, OVHAPIEndpointAll)
+	}
+
+	transport, err := newTransport(cmd, ttls)
+	if err != nil {
+		return nil, err
+	}
+
+	return kimsufi.NewServiceWithTransport(endpoint, transport, log.StandardLogger(), nil)
+}
+
+// MaxConcurrentEndpoints is the maximum number of OVH endpoints queried at once with --endpoint all.
+const MaxConcurrentEndpoints = 3
+
+// IsAllEndpoints returns true when --endpoint is set to all.
+func IsAllEndpoints(cmd *cobra.Command) bool {
+	return cmd.Flag(OVHAPIEndpointFlagName).Value.String() == OVHAPIEndpointAll
+}
+
+// NewMultiService returns the kimsufi services for all the OVH endpoints,
+// configured the same way as NewService.
+// Recording and replaying is not supported, the endpoints interactions would be mixed up in the cassette.
+func NewMultiService(cmd *cobra.Command) (kimsufi.MultiService, error) {
+	if cmd.Flag(RecordFlagName).Value.String() != "" || cmd.Flag(ReplayFlagName).Value.String() != "" {
+		return nil, fmt.Errorf("--%s and --%s are not supported with --%s %s", RecordFlagName, ReplayFlagName, OVHAPIEndpointFlagName, OVHAPIEndpointAll)
+	}
+
+	transport, err := newTransport(cmd, diskcache.DefaultTTLs)
+	if err != nil {
+		return nil, err
+	}
+
+	return kimsufi.NewMultiServiceWithTransport(transport, log.StandardLogger(), nil)
+}
+
+// EndpointCountry returns the country to use for the endpoint,
+// the --country flag when it is allowed for the endpoint, otherwise the endpoint default country.
+func EndpointCountry(cmd *cobra.Command, endpoint string) string {
+	country := cmd.Flag(CountryFlagName).Value.String()
+
+	region := kimsufiregion.GetRegionFromEndpoint(endpoint)
+	if region == nil || region.HasCountry(country) {
+		return country
+	}
+
+	return region.DefaultCountry
+}
+
+// newTransport returns the HTTP transport for the --record, --replay, --no-cache, --refresh and --max-attempts flags,
+// the responses of the API paths in ttls are cached on disk.
+func newTransport(cmd *cobra.Command, ttls []diskcache.TTL) (http.RoundTripper, error) {
+	record := cmd.Flag(RecordFlagName).Value.String()
+	replay := cmd.Flag(ReplayFlagName).Value.String()
+	noCache := cmd.Flag(NoCacheFlagName).Value.String() == "true"
+	refresh := cmd.Flag(RefreshFlagName).Value.String() == "true"
+
+	var transport http.RoundTripper
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", RecordFlagName, ReplayFlagName)
+	case record != "":
+		transport = cassette.NewRecorder(record, nil)
+	case replay != "":
+		c, err := cassette.Load(replay)
+		if err != nil {
+			return nil, err
+		}
+		transport = cassette.NewReplayer(c)
+	}
+
+	retryPolicy, err := newRetryPolicy(cmd)
+	if err != nil {
+		return nil, err
+	}
+	transport = kimsufi.NewRetryTransport(retryPolicy, transport, log.StandardLogger())
+
+	if record == "" && replay == "" && !noCache {
+		dir, err := diskcache.DefaultDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cache directory: %w", err)
+		}
+		cache := diskcache.New(dir)
+		cache.TTLs = ttls
+		transport = cache.Transport(transport, refresh)
+	}
+
+	return transport, nil
+}
+
+// newRetryPolicy returns the retry policy for the --max-attempts flag,
+// the cart requests are only retried with --retry-cart when the command has it.
+func newRetryPolicy(cmd *cobra.Command) (kimsufi.RetryPolicy, error) {
+	policy := kimsufi.DefaultRetryPolicy
+
+	maxAttempts, err := strconv.Atoi(cmd.Flag(MaxAttemptsFlagName).Value.String())
+	if err != nil {
+		return policy, err
+	}
+	if maxAttempts < 1 {
+		return policy, fmt.Errorf("--%s must be greater than 0", MaxAttemptsFlagName)
+	}
+	policy.MaxAttempts = maxAttempts
+
+	if f := cmd.Flag(RetryCartFlagName); f != nil {
+		policy.RetryNonIdempotent = f.Value.String() == "true"
+	}
+
+	return policy, nil
+}
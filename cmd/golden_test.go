@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/ovhfake"
+)
+
+var update = flag.Bool("update", false, "record the cassettes against the fake OVH API and update the golden files")
+
+// TestGolden runs the commands against the cassettes in testdata and compares their output to the golden files.
+func TestGolden(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "list",
+			args: []string{"list", "--category", "kimsufi"},
+		},
+		{
+			name: "list-json",
+			args: []string{"list", "--category", "kimsufi", "--output", "json"},
+		},
+		{
+			name: "check",
+			args: []string{"check", "--plan-code", "24ska01"},
+		},
+		{
+			name: "compare",
+			args: []string{"compare", "24ska01", "24sk10"},
+		},
+		{
+			name: "prices",
+			args: []string{"prices", "24ska01", "24sk10"},
+		},
+		{
+			name: "search",
+			args: []string{"search", "--min-ram", "32", "--cpu-brand", "intel", "--where", "storage_tb >= 4 && frequency > 3"},
+		},
+		{
+			name: "order-dry-run",
+			args: []string{"order", "--plan-code", "24ska01", "--datacenters", "gra", "--dry-run"},
+		},
+	}
+
+	// Do not load the user configuration file
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(resetFlags)
+
+			cassettePath := filepath.Join("testdata", tc.name+".json")
+			goldenPath := filepath.Join("testdata", tc.name+".golden")
+
+			var args []string
+			if *update {
+				server := httptest.NewServer(ovhfake.New(ovhfake.DefaultScenario()))
+				defer server.Close()
+
+				args = append(tc.args, "--endpoint", server.URL, "--record", cassettePath, "--replay=")
+			} else {
+				args = append(tc.args, "--endpoint", "http://replay.invalid", "--record=", "--replay", cassettePath)
+			}
+
+			output := runCommand(t, args)
+
+			if *update {
+				err := os.WriteFile(goldenPath, output, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			golden, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(string(golden), string(output)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// runCommand executes the root command with args and returns what it wrote to stdout.
+func runCommand(t *testing.T, args []string) []byte {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r) // nolint:errcheck
+		output <- buf.Bytes()
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	w.Close() // nolint:errcheck
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	return <-output
+}
+
+// resetFlags sets back the flags of all the commands to their default value,
+// the commands and their flags variables are shared between the tests.
+func resetFlags() {
+	var reset func(cmd *cobra.Command)
+	reset = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.LocalNonPersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if s, ok := f.Value.(pflag.SliceValue); ok {
+					s.Replace(parseSliceDefault(f.DefValue)) // nolint:errcheck
+				} else if f.Value.String() != f.DefValue {
+					f.Value.Set(f.DefValue) // nolint:errcheck
+				}
+				f.Changed = false
+			})
+		}
+
+		for _, c := range cmd.Commands() {
+			reset(c)
+		}
+	}
+
+	reset(rootCmd)
+}
+
+// parseSliceDefault parses the default value of a slice flag, e.g. [a,b].
+func parseSliceDefault(s string) []string {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		return []string{}
+	}
+
+	return strings.Split(s, ",")
+}
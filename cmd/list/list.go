@@ -1,9 +1,12 @@
 package list
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -12,10 +15,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/notify"
 	pkgcategory "github.com/TheoBrigitte/kimsufi-notifier/pkg/category"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/expr"
 	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
 	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
 	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/hardware"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
 )
 
 var (
@@ -24,8 +32,11 @@ var (
 		Short: "List available servers",
 		Long:  "List servers from OVH Eco (including Kimsufi) catalog",
 		Example: `  kimsufi-notifier list --category kimsufi
-  kimsufi-notifier list --country US --endpoint ovh-us`,
-		RunE: runner,
+  kimsufi-notifier list --country US --endpoint ovh-us
+  kimsufi-notifier list --category kimsufi --endpoint all
+  kimsufi-notifier list --where 'ram >= 32 && ecc && price < 15 && dc in ["gra","rbx"]'`,
+		RunE:        runner,
+		Annotations: map[string]string{flag.ConfigAnnotation: "true", flag.OutputAnnotation: "true"},
 	}
 
 	// Flags variables
@@ -33,43 +44,175 @@ var (
 	datacenters []string
 	humanLevel  int
 	planCode    string
+	where       string
+
+	notifyFlags notify.Flags
+
+	// whereExpr is the compiled where flag expression, nil when not set.
+	whereExpr *expr.Expr
 )
 
+// Result represents a server plan and its availability,
+// it is the schema of the json, jsonl, yaml and csv outputs.
+type Result struct {
+	// Endpoint is the OVH endpoint of the result, only set with --endpoint all.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// Country is the OVH subsidiary of the catalog, only set with --endpoint all.
+	Country     string  `json:"country,omitempty" yaml:"country,omitempty"`
+	PlanCode    string  `json:"planCode" yaml:"planCode"`
+	Category    string  `json:"category" yaml:"category"`
+	InvoiceName string  `json:"invoiceName" yaml:"invoiceName"`
+	Price       float64 `json:"price" yaml:"price"`
+	Currency    string  `json:"currency" yaml:"currency"`
+	// Status is either available or unavailable.
+	Status string `json:"status" yaml:"status"`
+	// Datacenters holds the codes of the datacenters where the server is available.
+	Datacenters []string `json:"datacenters" yaml:"datacenters"`
+	// DatacenterNames holds the full names of the datacenters where the server is available.
+	DatacenterNames []string `json:"datacenterNames" yaml:"datacenterNames"`
+	// Memory holds the memory of the available configurations (e.g. ram-32g-noecc-2133).
+	Memory []string `json:"memory" yaml:"memory"`
+	// Storage holds the storage of the available configurations (e.g. softraid-2x2000sa).
+	Storage []string `json:"storage" yaml:"storage"`
+}
+
 // init registers all flags
 func init() {
 	flag.BindCategoryFlag(Cmd, &category)
 	flag.BindDatacentersFlag(Cmd, &datacenters)
 	flag.BindHumanFlag(Cmd, &humanLevel)
+	flag.BindWhereFlag(Cmd, &where)
+	notify.Bind(Cmd, &notifyFlags)
 
 	Cmd.PersistentFlags().StringVarP(&planCode, flag.PlanCodeFlagName, flag.PlanCodeFlagShortName, "", fmt.Sprintf("plan code to filter on (e.g. %s)", flag.PlanCodeExample))
 }
 
 // runner is the main function for the list command
 func runner(cmd *cobra.Command, args []string) error {
-	// Initialize kimsufi service
-	endpoint := cmd.Flag(flag.OVHAPIEndpointFlagName).Value.String()
-	k, err := kimsufi.NewService(endpoint, log.StandardLogger(), nil)
+	var err error
+	whereExpr, err = flag.CompileWhere(where)
+	if err != nil {
+		return err
+	}
+
+	printer, err := output.NewPrinter(cmd.Flag(flag.OutputFlagName).Value.String(), cmd.Flag(flag.TemplateFileFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	notifiers, err := notifyFlags.NewRegistry()
 	if err != nil {
 		return fmt.Errorf("error: %w", err)
 	}
 
+	var (
+		events  []notifier.Event
+		results []Result
+	)
+	if flag.IsAllEndpoints(cmd) {
+		results, events, err = listAllEndpoints(cmd)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Initialize kimsufi service
+		k, err := flag.NewService(cmd)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+
+		results, events, err = listServers(cmd.Context(), k, cmd.Flag(flag.CountryFlagName).Value.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	if printer.Format == output.FormatTable {
+		printTable(results)
+	} else {
+		err = printer.Print(os.Stdout, results)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	notify.Send(cmd.Context(), notifiers, events)
+
+	if len(events) == 0 {
+		// Nothing available
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// listAllEndpoints lists the servers of all the OVH endpoints concurrently.
+// Each result keeps the currency of its endpoint catalog, an endpoint failing is logged and skipped.
+func listAllEndpoints(cmd *cobra.Command) ([]Result, []notifier.Event, error) {
+	m, err := flag.NewMultiService(cmd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error: %w", err)
+	}
+
+	type endpointList struct {
+		results []Result
+		events  []notifier.Event
+	}
+
+	endpointResults := kimsufi.RunAll(cmd.Context(), m, flag.MaxConcurrentEndpoints, func(ctx context.Context, endpoint string, k *kimsufi.Service) (endpointList, error) {
+		country := flag.EndpointCountry(cmd, endpoint)
+
+		results, events, err := listServers(ctx, k, country)
+		for i := range results {
+			results[i].Endpoint = endpoint
+			results[i].Country = country
+		}
+
+		return endpointList{results: results, events: events}, err
+	})
+
+	var (
+		events  []notifier.Event
+		results []Result
+		errs    []error
+	)
+	for _, r := range endpointResults {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+
+		results = append(results, r.Value.results...)
+		events = append(events, r.Value.events...)
+	}
+
+	if len(errs) == len(endpointResults) {
+		return nil, nil, fmt.Errorf("failed to list servers on all endpoints: %w", errors.Join(errs...))
+	}
+
+	// Report the failing endpoints and keep the results of the others
+	for _, err := range errs {
+		log.Error(err)
+	}
+
+	return results, events, nil
+}
+
+// listServers returns the servers of the catalog for the country, sorted by category and price,
+// and the notification events of the available ones.
+func listServers(ctx context.Context, k *kimsufi.Service, country string) ([]Result, []notifier.Event, error) {
 	// List servers
-	catalog, err := k.ListServers(cmd.Flag(flag.CountryFlagName).Value.String())
+	catalog, err := k.ListServersWithContext(ctx, country)
 	if err != nil {
-		return fmt.Errorf("failed to list servers: %w", err)
+		return nil, nil, fmt.Errorf("failed to list servers: %w", err)
 	}
 
 	// List availabilities
-	availabilities, err := k.GetAvailabilities(datacenters, planCode, nil)
+	availabilities, err := k.GetAvailabilitiesWithContext(ctx, datacenters, planCode, nil)
 	if err != nil {
-		return fmt.Errorf("failed to list availabilities: %w", err)
+		return nil, nil, fmt.Errorf("failed to list availabilities: %w", err)
 	}
 
-	// Display servers availabilities
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
-	fmt.Fprintln(w, "planCode\tcategory\tname\tprice\tstatus\tdatacenters") // nolint:errcheck
-	fmt.Fprintln(w, "--------\t--------\t----\t-----\t------\t-----------") // nolint:errcheck
-
 	// Sort plans by category and price
 	sort.Slice(catalog.Plans, func(i, j int) bool {
 		planCategoryI := catalog.Plans[i].GetCategory()
@@ -93,8 +236,10 @@ func runner(cmd *cobra.Command, args []string) error {
 		return catalog.Plans[i].GetFirstPrice().Price < catalog.Plans[j].GetFirstPrice().Price
 	})
 
-	// Display servers plans
-	nothingAvailable := true
+	var (
+		events  []notifier.Event
+		results []Result
+	)
 	for _, plan := range catalog.Plans {
 		// Filter plans by plan code code
 		if planCode != "" && plan.PlanCode != planCode {
@@ -108,6 +253,11 @@ func runner(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		// Filter plans by expression
+		if whereExpr != nil && !matchWhere(catalog, &plan, *availabilities) {
+			continue
+		}
+
 		// Format price
 		var price float64
 		planPrice := plan.GetFirstPrice()
@@ -116,33 +266,96 @@ func runner(cmd *cobra.Command, args []string) error {
 		}
 
 		// Format availability status
-		datacenters := availabilities.GetByPlanCode(plan.PlanCode).GetAvailableDatacenters()
+		planAvailabilities := availabilities.GetByPlanCode(plan.PlanCode)
+		datacenters := planAvailabilities.GetAvailableDatacenters()
 
-		var datacenterNames []string
-		if humanLevel > 0 {
-			datacenterNames = datacenters.ToFullNamesOrCodes()
-		} else {
-			datacenterNames = datacenters.Codes()
+		status := datacenters.Status()
+
+		// Notify about each available configuration, with its own memory and storage.
+		memory, storage := []string{}, []string{}
+		for _, a := range planAvailabilities {
+			configurationDatacenters := a.GetAvailableDatacenters()
+			if configurationDatacenters.Status() != kimsufiavailability.StatusAvailable {
+				continue
+			}
+
+			events = append(events, notifier.NewEvent(catalog, plan.PlanCode, a.Memory, a.Storage, configurationDatacenters))
+
+			if !slices.Contains(memory, a.Memory) {
+				memory = append(memory, a.Memory)
+			}
+			if !slices.Contains(storage, a.Storage) {
+				storage = append(storage, a.Storage)
+			}
 		}
 
-		status := datacenters.Status()
-		if status == kimsufiavailability.StatusAvailable {
-			nothingAvailable = false
+		r := Result{
+			PlanCode:        plan.PlanCode,
+			Category:        planCategory,
+			InvoiceName:     plan.InvoiceName,
+			Price:           price,
+			Currency:        catalog.Locale.CurrencyCode,
+			Status:          status,
+			Datacenters:     datacenters.Codes(),
+			DatacenterNames: datacenters.ToFullNamesOrCodes(),
+			Memory:          memory,
+			Storage:         storage,
 		}
 
-		categoryDisplay := pkgcategory.GetDisplayName(planCategory)
-		if categoryDisplay == "" {
-			categoryDisplay = planCategory
+		// Always encode empty lists instead of null.
+		if r.Datacenters == nil {
+			r.Datacenters = []string{}
+			r.DatacenterNames = []string{}
 		}
 
-		// Display plan
-		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f %s\t%s\t%s\n", plan.PlanCode, categoryDisplay, plan.InvoiceName, price, catalog.Locale.CurrencyCode, status, strings.Join(datacenterNames, ", ")) // nolint:errcheck
+		results = append(results, r)
 	}
-	w.Flush() // nolint:errcheck
 
-	if nothingAvailable {
-		os.Exit(1)
+	return results, events, nil
+}
+
+// matchWhere returns true if any configuration of the plan matches the where expression.
+func matchWhere(catalog *kimsuficatalog.Catalog, plan *kimsuficatalog.Plan, availabilities kimsufiavailability.Availabilities) bool {
+	for _, c := range hardware.Configurations(catalog, plan) {
+		if whereExpr.Match(c.Row(c.Availabilities(availabilities).GetAvailableDatacenters())) {
+			return true
+		}
 	}
 
-	return nil
+	return false
+}
+
+// printTable displays the servers plans as a table.
+func printTable(results []Result) {
+	allEndpoints := slices.ContainsFunc(results, func(r Result) bool {
+		return r.Endpoint != ""
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	if allEndpoints {
+		fmt.Fprint(w, "endpoint\tcountry\t") // nolint:errcheck
+	}
+	fmt.Fprintln(w, "planCode\tcategory\tname\tprice\tstatus\tdatacenters") // nolint:errcheck
+	if allEndpoints {
+		fmt.Fprint(w, "--------\t-------\t") // nolint:errcheck
+	}
+	fmt.Fprintln(w, "--------\t--------\t----\t-----\t------\t-----------") // nolint:errcheck
+
+	for _, r := range results {
+		categoryDisplay := pkgcategory.GetDisplayName(r.Category)
+		if categoryDisplay == "" {
+			categoryDisplay = r.Category
+		}
+
+		datacenterNames := r.Datacenters
+		if humanLevel > 0 {
+			datacenterNames = r.DatacenterNames
+		}
+
+		if allEndpoints {
+			fmt.Fprintf(w, "%s\t%s\t", r.Endpoint, r.Country) // nolint:errcheck
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f %s\t%s\t%s\n", r.PlanCode, categoryDisplay, r.InvoiceName, r.Price, r.Currency, r.Status, strings.Join(datacenterNames, ", ")) // nolint:errcheck
+	}
+	w.Flush() // nolint:errcheck
 }
@@ -0,0 +1,50 @@
+package list
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/ovhfake"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+func TestListServersEvents(t *testing.T) {
+	server := httptest.NewServer(ovhfake.New(ovhfake.DefaultScenario()))
+	defer server.Close()
+
+	k, err := kimsufi.NewServiceWithTransport(server.URL, nil, log.StandardLogger(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, events, err := listServers(context.Background(), k, "FR")
+	if err != nil {
+		t.Fatalf("listServers failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	// The events of the list command hold the memory and storage of the available configuration,
+	// they are exposed to the --on-available hook as KIMSUFI_MEMORY and KIMSUFI_STORAGE.
+	expected := notifier.Event{
+		PlanCode:        "24ska01",
+		InvoiceName:     "KS-A | Intel i7-6700k",
+		Memory:          "ram-32g-noecc-2133",
+		Storage:         "softraid-2x2000sa",
+		Datacenters:     []string{"Gravelines (France)", "Roubaix (France)"},
+		DatacenterCodes: []string{"gra", "rbx"},
+		Price:           12.99,
+		Currency:        "EUR",
+		Subsidiary:      "FR",
+	}
+	if diff := cmp.Diff(expected, events[0], cmp.FilterPath(func(p cmp.Path) bool { return p.String() == "Specs" }, cmp.Ignore())); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%s", diff)
+	}
+}
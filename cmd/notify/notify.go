@@ -0,0 +1,353 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/discord"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/email"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/gotify"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/hook"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/ntfy"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/slack"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/telegram"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier/webhook"
+)
+
+const (
+	TelegramFlagName               = "notify-telegram"
+	TelegramChatIDFlagName         = "telegram-chat-id"
+	TelegramTokenFlagName          = "telegram-token"
+	TelegramTokenEnvVarNameDefault = "TELEGRAM_BOT_TOKEN"
+	TelegramURLFlagName            = "telegram-url"
+
+	DiscordFlagName                    = "notify-discord"
+	DiscordWebhookURLFlagName          = "discord-webhook-url"
+	DiscordWebhookURLEnvVarNameDefault = "DISCORD_WEBHOOK_URL"
+
+	SlackFlagName                    = "notify-slack"
+	SlackWebhookURLFlagName          = "slack-webhook-url"
+	SlackWebhookURLEnvVarNameDefault = "SLACK_WEBHOOK_URL"
+
+	NtfyFlagName               = "notify-ntfy"
+	NtfyPriorityFlagName       = "ntfy-priority"
+	NtfyTagsFlagName           = "ntfy-tags"
+	NtfyTokenFlagName          = "ntfy-token"
+	NtfyTokenEnvVarNameDefault = "NTFY_TOKEN"
+	NtfyTopicFlagName          = "ntfy-topic"
+	NtfyURLFlagName            = "ntfy-url"
+
+	GotifyFlagName               = "notify-gotify"
+	GotifyPriorityFlagName       = "gotify-priority"
+	GotifyTokenFlagName          = "gotify-token"
+	GotifyTokenEnvVarNameDefault = "GOTIFY_TOKEN"
+	GotifyURLFlagName            = "gotify-url"
+
+	EmailFlagName                 = "notify-email"
+	EmailFromFlagName             = "email-from"
+	EmailToFlagName               = "email-to"
+	SMTPAuthFlagName              = "smtp-auth"
+	SMTPHostFlagName              = "smtp-host"
+	SMTPPasswordFlagName          = "smtp-password"
+	SMTPPasswordEnvVarNameDefault = "SMTP_PASSWORD"
+	SMTPPortFlagName              = "smtp-port"
+	SMTPTLSFlagName               = "smtp-tls"
+	SMTPUsernameFlagName          = "smtp-username"
+
+	OnAvailableFlagName        = "on-available"
+	OnAvailableTimeoutFlagName = "on-available-timeout"
+
+	WebhookFlagName                = "notify-webhook"
+	WebhookHeaderFlagName          = "webhook-header"
+	WebhookRetriesFlagName         = "webhook-retries"
+	WebhookSecretFlagName          = "webhook-secret"
+	WebhookSecretEnvVarNameDefault = "WEBHOOK_SECRET"
+	WebhookTemplateFlagName        = "webhook-template"
+	WebhookTemplateFileFlagName    = "webhook-template-file"
+	WebhookTimeoutFlagName         = "webhook-timeout"
+	WebhookURLFlagName             = "webhook-url"
+)
+
+// Flags holds the notification flags values.
+type Flags struct {
+	Telegram                bool
+	TelegramChatID          string
+	TelegramTokenEnvVarName string
+	TelegramURL             string
+
+	Discord                     bool
+	DiscordWebhookURLEnvVarName string
+	Slack                       bool
+	SlackWebhookURLEnvVarName   string
+
+	Ntfy                bool
+	NtfyTopic           string
+	NtfyPriority        string
+	NtfyTags            []string
+	NtfyTokenEnvVarName string
+	NtfyURL             string
+
+	Gotify                bool
+	GotifyPriority        string
+	GotifyTokenEnvVarName string
+	GotifyURL             string
+
+	Email                  bool
+	EmailFrom              string
+	EmailTo                []string
+	SMTPAuth               string
+	SMTPHost               string
+	SMTPPasswordEnvVarName string
+	SMTPPort               int
+	SMTPTLS                string
+	SMTPUsername           string
+
+	OnAvailable        string
+	OnAvailableTimeout time.Duration
+
+	Webhook                 bool
+	WebhookURL              string
+	WebhookHeaders          map[string]string
+	WebhookRetries          int
+	WebhookSecretEnvVarName string
+	WebhookTemplate         string
+	WebhookTemplateFile     string
+	WebhookTimeout          time.Duration
+}
+
+// Bind binds the notification flags to the provided cmd and value.
+func Bind(cmd *cobra.Command, f *Flags) {
+	cmd.PersistentFlags().BoolVar(&f.Telegram, TelegramFlagName, false, "send a Telegram message when servers are available")
+	cmd.PersistentFlags().StringVar(&f.TelegramChatID, TelegramChatIDFlagName, "", "Telegram chat ID to send messages to")
+	cmd.PersistentFlags().StringVar(&f.TelegramTokenEnvVarName, TelegramTokenFlagName, TelegramTokenEnvVarNameDefault, "environment variable name for Telegram bot token")
+	cmd.PersistentFlags().StringVar(&f.TelegramURL, TelegramURLFlagName, telegram.BaseURLDefault, "Telegram Bot API base URL")
+
+	cmd.PersistentFlags().BoolVar(&f.Discord, DiscordFlagName, false, "send a Discord message when servers are available")
+	cmd.PersistentFlags().StringVar(&f.DiscordWebhookURLEnvVarName, DiscordWebhookURLFlagName, DiscordWebhookURLEnvVarNameDefault, "environment variable name for Discord incoming webhook URL")
+
+	cmd.PersistentFlags().BoolVar(&f.Slack, SlackFlagName, false, "send a Slack message when servers are available")
+	cmd.PersistentFlags().StringVar(&f.SlackWebhookURLEnvVarName, SlackWebhookURLFlagName, SlackWebhookURLEnvVarNameDefault, "environment variable name for Slack incoming webhook URL")
+
+	cmd.PersistentFlags().BoolVar(&f.Ntfy, NtfyFlagName, false, "publish a ntfy message when servers are available")
+	cmd.PersistentFlags().StringVar(&f.NtfyTopic, NtfyTopicFlagName, "", "ntfy topic to publish to")
+	cmd.PersistentFlags().StringVar(&f.NtfyPriority, NtfyPriorityFlagName, "default", "ntfy message priority (allowed values: min, low, default, high, max, urgent)")
+	cmd.PersistentFlags().StringSliceVar(&f.NtfyTags, NtfyTagsFlagName, nil, "ntfy message tags, comma separated list (e.g. computer,tada)")
+	cmd.PersistentFlags().StringVar(&f.NtfyTokenEnvVarName, NtfyTokenFlagName, NtfyTokenEnvVarNameDefault, "environment variable name for ntfy access token, optional")
+	cmd.PersistentFlags().StringVar(&f.NtfyURL, NtfyURLFlagName, ntfy.BaseURLDefault, "ntfy server URL")
+
+	cmd.PersistentFlags().BoolVar(&f.Gotify, GotifyFlagName, false, "send a Gotify message when servers are available")
+	cmd.PersistentFlags().StringVar(&f.GotifyPriority, GotifyPriorityFlagName, "default", "Gotify message priority (allowed values: min, low, default, high, urgent or 0 to 10)")
+	cmd.PersistentFlags().StringVar(&f.GotifyTokenEnvVarName, GotifyTokenFlagName, GotifyTokenEnvVarNameDefault, "environment variable name for Gotify application token")
+	cmd.PersistentFlags().StringVar(&f.GotifyURL, GotifyURLFlagName, "", "Gotify server URL")
+
+	cmd.PersistentFlags().BoolVar(&f.Email, EmailFlagName, false, "send an email when servers are available")
+	cmd.PersistentFlags().StringVar(&f.EmailFrom, EmailFromFlagName, "", "email sender address")
+	cmd.PersistentFlags().StringSliceVar(&f.EmailTo, EmailToFlagName, nil, "email recipients addresses, comma separated list")
+	cmd.PersistentFlags().StringVar(&f.SMTPAuth, SMTPAuthFlagName, email.AuthModePlain, fmt.Sprintf("SMTP authentication mechanism (allowed values: %s)", strings.Join(email.AuthModes, ", ")))
+	cmd.PersistentFlags().StringVar(&f.SMTPHost, SMTPHostFlagName, "", "SMTP server host")
+	cmd.PersistentFlags().StringVar(&f.SMTPPasswordEnvVarName, SMTPPasswordFlagName, SMTPPasswordEnvVarNameDefault, "environment variable name for SMTP password")
+	cmd.PersistentFlags().IntVar(&f.SMTPPort, SMTPPortFlagName, email.PortDefault, "SMTP server port")
+	cmd.PersistentFlags().StringVar(&f.SMTPTLS, SMTPTLSFlagName, email.TLSModeSTARTTLS, fmt.Sprintf("SMTP connection encryption (allowed values: %s)", strings.Join(email.TLSModes, ", ")))
+	cmd.PersistentFlags().StringVar(&f.SMTPUsername, SMTPUsernameFlagName, "", "SMTP username")
+
+	cmd.PersistentFlags().StringVar(&f.OnAvailable, OnAvailableFlagName, "", fmt.Sprintf("command to run for every available server, the result is passed as JSON on stdin and as %s* environment variables", hook.EnvVarPrefix))
+	cmd.PersistentFlags().DurationVar(&f.OnAvailableTimeout, OnAvailableTimeoutFlagName, hook.TimeoutDefault, "maximum duration of the --"+OnAvailableFlagName+" command")
+
+	cmd.PersistentFlags().BoolVar(&f.Webhook, WebhookFlagName, false, "POST a JSON payload to a webhook when servers are available")
+	cmd.PersistentFlags().StringVar(&f.WebhookURL, WebhookURLFlagName, "", "webhook URL to POST the JSON payload to")
+	cmd.PersistentFlags().StringToStringVar(&f.WebhookHeaders, WebhookHeaderFlagName, nil, "webhook headers, comma separated list of key=value (e.g. Authorization=Bearer xxx)")
+	cmd.PersistentFlags().IntVar(&f.WebhookRetries, WebhookRetriesFlagName, webhook.RetriesDefault, "number of times a failed webhook request is retried")
+	cmd.PersistentFlags().StringVar(&f.WebhookSecretEnvVarName, WebhookSecretFlagName, WebhookSecretEnvVarNameDefault, fmt.Sprintf("environment variable name for webhook secret, when set the body is signed with HMAC-SHA256 in the %s header", webhook.SignatureHeader))
+	cmd.PersistentFlags().StringVar(&f.WebhookTemplate, WebhookTemplateFlagName, webhook.TemplateDefault, "webhook body Go template, executed over the availability result (e.g. {\"text\": {{ json .InvoiceName }}})")
+	cmd.PersistentFlags().StringVar(&f.WebhookTemplateFile, WebhookTemplateFileFlagName, "", "file containing the webhook body Go template, overrides --"+WebhookTemplateFlagName)
+	cmd.PersistentFlags().DurationVar(&f.WebhookTimeout, WebhookTimeoutFlagName, webhook.TimeoutDefault, "webhook request timeout")
+}
+
+// NewRegistry creates a notifier registry holding the notifiers enabled by the flags.
+func (f Flags) NewRegistry() (*notifier.Registry, error) {
+	r := notifier.NewRegistry()
+
+	if f.Telegram {
+		if f.TelegramChatID == "" {
+			return nil, fmt.Errorf("--%s is required", TelegramChatIDFlagName)
+		}
+
+		token := os.Getenv(f.TelegramTokenEnvVarName)
+		if token == "" {
+			return nil, fmt.Errorf("%s env var is required", f.TelegramTokenEnvVarName)
+		}
+
+		t := telegram.New(token, f.TelegramChatID)
+		t.BaseURL = f.TelegramURL
+
+		err := r.Register("telegram", t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Discord {
+		webhookURL := os.Getenv(f.DiscordWebhookURLEnvVarName)
+		if webhookURL == "" {
+			return nil, fmt.Errorf("%s env var is required", f.DiscordWebhookURLEnvVarName)
+		}
+
+		err := r.Register("discord", discord.New(webhookURL))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Slack {
+		webhookURL := os.Getenv(f.SlackWebhookURLEnvVarName)
+		if webhookURL == "" {
+			return nil, fmt.Errorf("%s env var is required", f.SlackWebhookURLEnvVarName)
+		}
+
+		err := r.Register("slack", slack.New(webhookURL))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Ntfy {
+		if f.NtfyTopic == "" {
+			return nil, fmt.Errorf("--%s is required", NtfyTopicFlagName)
+		}
+
+		priority, err := ntfy.ParsePriority(f.NtfyPriority)
+		if err != nil {
+			return nil, err
+		}
+
+		n := ntfy.New(f.NtfyTopic)
+		n.BaseURL = f.NtfyURL
+		n.Priority = priority
+		n.Tags = f.NtfyTags
+		n.Token = os.Getenv(f.NtfyTokenEnvVarName)
+
+		err = r.Register("ntfy", n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Gotify {
+		if f.GotifyURL == "" {
+			return nil, fmt.Errorf("--%s is required", GotifyURLFlagName)
+		}
+
+		token := os.Getenv(f.GotifyTokenEnvVarName)
+		if token == "" {
+			return nil, fmt.Errorf("%s env var is required", f.GotifyTokenEnvVarName)
+		}
+
+		priority, err := gotify.ParsePriority(f.GotifyPriority)
+		if err != nil {
+			return nil, err
+		}
+
+		g := gotify.New(f.GotifyURL, token)
+		g.Priority = priority
+
+		err = r.Register("gotify", g)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Email {
+		if f.SMTPHost == "" {
+			return nil, fmt.Errorf("--%s is required", SMTPHostFlagName)
+		}
+		if f.EmailFrom == "" {
+			return nil, fmt.Errorf("--%s is required", EmailFromFlagName)
+		}
+		if len(f.EmailTo) == 0 {
+			return nil, fmt.Errorf("--%s is required", EmailToFlagName)
+		}
+		if !slices.Contains(email.AuthModes, f.SMTPAuth) {
+			return nil, fmt.Errorf("invalid --%s %q, allowed values: %s", SMTPAuthFlagName, f.SMTPAuth, strings.Join(email.AuthModes, ", "))
+		}
+		if !slices.Contains(email.TLSModes, f.SMTPTLS) {
+			return nil, fmt.Errorf("invalid --%s %q, allowed values: %s", SMTPTLSFlagName, f.SMTPTLS, strings.Join(email.TLSModes, ", "))
+		}
+
+		e := email.New(f.SMTPHost, f.EmailFrom, f.EmailTo)
+		e.Port = f.SMTPPort
+		e.Auth = f.SMTPAuth
+		e.TLS = f.SMTPTLS
+		e.Username = f.SMTPUsername
+		e.Password = os.Getenv(f.SMTPPasswordEnvVarName)
+
+		if e.Auth != email.AuthModeNone && e.Password == "" {
+			return nil, fmt.Errorf("%s env var is required", f.SMTPPasswordEnvVarName)
+		}
+
+		err := r.Register("email", e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.OnAvailable != "" {
+		h := hook.New(f.OnAvailable)
+		h.Timeout = f.OnAvailableTimeout
+
+		err := r.Register("on-available", h)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Webhook {
+		if f.WebhookURL == "" {
+			return nil, fmt.Errorf("--%s is required", WebhookURLFlagName)
+		}
+
+		text := f.WebhookTemplate
+		if f.WebhookTemplateFile != "" {
+			b, err := os.ReadFile(f.WebhookTemplateFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read webhook template: %w", err)
+			}
+			text = string(b)
+		}
+
+		tmpl, err := webhook.NewTemplate(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+
+		w := webhook.New(f.WebhookURL)
+		w.Template = tmpl
+		w.Headers = f.WebhookHeaders
+		w.Retries = f.WebhookRetries
+		w.Secret = os.Getenv(f.WebhookSecretEnvVarName)
+		w.Client.Timeout = f.WebhookTimeout
+
+		err = r.Register("webhook", w)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Send sends the events to all notifiers in the registry.
+// Errors are logged and do not stop the other notifiers from being notified.
+func Send(ctx context.Context, r *notifier.Registry, events []notifier.Event) {
+	err := r.SendAll(ctx, events)
+	if err != nil {
+		log.Errorf("failed to send notifications: %v", err)
+	}
+}
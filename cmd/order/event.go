@@ -0,0 +1,37 @@
+package order
+
+import (
+	"fmt"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/orderflow"
+)
+
+// PrintEvent displays the progress of the order flow.
+func PrintEvent(e orderflow.Event) {
+	switch e.Type {
+	case orderflow.EventCartCreated:
+		fmt.Printf("> cart created id=%s\n", e.CartID)
+	case orderflow.EventItemAdded:
+		fmt.Printf("> cart item added id=%d\n", e.ItemID)
+	case orderflow.EventItemConfigured:
+		fmt.Printf("> cart item configured: %s=%s\n", e.Label, e.Value)
+	case orderflow.EventPlanned:
+		fmt.Printf("> item options: %d %v\n", len(e.Plan.Options), e.Plan.Options.PlanCodes())
+		fmt.Printf("> datacenter(s): %d\n", len(e.Plan.Datacenters))
+		fmt.Printf("> combinations: %d\n", len(e.Plan.Attempts()))
+	case orderflow.EventDryRun:
+		fmt.Println("> dry-run enabled, skipping order submission")
+	case orderflow.EventCartAssigned:
+		fmt.Println("> cart assigned")
+	case orderflow.EventOptionSet:
+		fmt.Printf("> cart option set: %s=%s\n", e.Label, e.Value)
+	case orderflow.EventDatacenterConfigured:
+		fmt.Printf("> datacenter %s configured\n", e.Datacenter)
+	case orderflow.EventDatacenterNotAvailable:
+		fmt.Printf("> datacenter %s not available\n", e.Datacenter)
+	case orderflow.EventCheckoutFailed:
+		fmt.Printf("> error: %v\n", e.Err)
+	case orderflow.EventOrderCompleted:
+		fmt.Printf("> order completed: %s\n", e.Checkout.URL)
+	}
+}
@@ -1,12 +1,31 @@
 package cmd
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/notify"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
 )
 
+// preRun runs before every command
+func preRun(cmd *cobra.Command, args []string) error {
+	err := logLevel(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	err = flag.ValidateOutput(cmd)
+	if err != nil {
+		return err
+	}
+
+	return applyConfig(cmd, args)
+}
+
 // logLevel set the logger log level using the value of the flag
 func logLevel(cmd *cobra.Command, args []string) error {
 	level, err := log.ParseLevel(cmd.Flag(flag.LogLevelFlagName).Value.String())
@@ -17,3 +36,57 @@ func logLevel(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// applyConfig loads the configuration file for the commands using it
+// and sets the flags values from it, flags set on the command line take precedence.
+// The configuration is kept in the command context, see flag.Config.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	if !flag.UsesConfig(cmd) {
+		return nil
+	}
+
+	c, err := flag.LoadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if c == nil {
+		return nil
+	}
+	cmd.SetContext(flag.WithConfig(cmd.Context(), c))
+
+	values := map[string]string{
+		flag.OVHAPIEndpointFlagName: c.Endpoint,
+		flag.CountryFlagName:        c.Country,
+		flag.OVHAppKeyFlagName:      c.Credentials.AppKey,
+		flag.OVHAppSecretFlagName:   c.Credentials.AppSecret,
+		flag.OVHConsumerKeyFlagName: c.Credentials.ConsumerKey,
+		notify.TelegramURLFlagName:  c.Notify.TelegramURL,
+		notify.NtfyURLFlagName:      c.Notify.NtfyURL,
+		notify.GotifyURLFlagName:    c.Notify.GotifyURL,
+	}
+
+	for name, value := range values {
+		f := cmd.Flag(name)
+		if value == "" || f == nil || f.Changed {
+			continue
+		}
+
+		err := f.Value.Set(value)
+		if err != nil {
+			return fmt.Errorf("invalid configuration %s: %w", name, err)
+		}
+		log.Debugf("%s set to %q from configuration", name, value)
+	}
+
+	// The configuration is validated against its own endpoint when loaded,
+	// the country must also be allowed for the effective endpoint.
+	endpoint := cmd.Flag(flag.OVHAPIEndpointFlagName).Value.String()
+	if c.Country != "" && !cmd.Flag(flag.CountryFlagName).Changed && endpoint != flag.OVHAPIEndpointAll {
+		err := config.ValidateCountry(endpoint, c.Country)
+		if err != nil {
+			return fmt.Errorf("invalid configuration country: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/config"
+)
+
+// TestApplyConfig checks the configuration file is only loaded for the commands using it.
+func TestApplyConfig(t *testing.T) {
+	t.Cleanup(resetFlags)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	path := filepath.Join(dir, config.DirName, config.FileName)
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte("endpoint: [malformed\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runCommand(t, []string{"version"})
+	runCommand(t, []string{"cache", "info"})
+
+	rootCmd.SetArgs([]string{"list", "--endpoint", "http://replay.invalid", "--record=", "--replay", filepath.Join("testdata", "list.json")})
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid configuration") {
+		t.Errorf("expected invalid configuration error, got %v", err)
+	}
+
+	// The configuration country is validated against the endpoint set on the command line
+	err = os.WriteFile(path, []byte("country: FR\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.SetArgs([]string{"list", "--endpoint", "ovh-ca", "--record=", "--replay", filepath.Join("testdata", "list.json")})
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), `country "FR" is not allowed for endpoint ovh-ca`) {
+		t.Errorf("expected country not allowed error, got %v", err)
+	}
+}
+
+// TestValidateOutput checks the output flags are rejected by the commands which do not support them.
+func TestValidateOutput(t *testing.T) {
+	t.Cleanup(resetFlags)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	testCases := [][]string{
+		{"cache", "info", "--output", "json"},
+		{"watch", "--template-file", "template.tmpl"},
+		{"order", "--plan-code", "24ska01", "--output", "yaml"},
+		{"check", "--plan-code", "24ska01", "--auto-order", "--output", "json"},
+	}
+
+	for _, args := range testCases {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			t.Cleanup(resetFlags)
+
+			rootCmd.SetArgs(args)
+			err := rootCmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), "support") {
+				t.Errorf("expected unsupported output error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package prices
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:   "prices [PLAN...]",
+		Short: "Show server prices breakdown",
+		Long: `Show every price of OVH Eco (including Kimsufi) server plans: setup fees, monthly, degressive and upfront prices.
+
+Totals include the setup fee and are computed over 1, 12 and 24 months, a price is charged for at least its commitment.
+The effective monthly price is the cost per month over the minimum term of the price, the cheapest one of each plan is marked with *.`,
+		Example: `  kimsufi-notifier prices 24ska01 24sk40
+  kimsufi-notifier prices --category kimsufi --tax
+  kimsufi-notifier prices 24ska01 --output json`,
+		RunE:        runner,
+		Annotations: map[string]string{flag.ConfigAnnotation: "true", flag.OutputAnnotation: "true"},
+	}
+
+	// Flags variables
+	category string
+	withTax  bool
+)
+
+// totalMonths are the durations over which the totals are computed.
+var totalMonths = []int{1, 12, 24}
+
+// Result represents a price of a server plan,
+// it is the schema of the json, jsonl, yaml and csv outputs.
+type Result struct {
+	PlanCode    string   `json:"planCode" yaml:"planCode"`
+	InvoiceName string   `json:"invoiceName" yaml:"invoiceName"`
+	Phase       int      `json:"phase" yaml:"phase"`
+	Capacities  []string `json:"capacities" yaml:"capacities"`
+	// Interval is the number of IntervalUnit between charges, 0 for prices charged once like setup fees.
+	Interval     int    `json:"interval" yaml:"interval"`
+	IntervalUnit string `json:"intervalUnit" yaml:"intervalUnit"`
+	Mode         string `json:"mode" yaml:"mode"`
+	// Commitment is the commitment duration in months.
+	Commitment int    `json:"commitment" yaml:"commitment"`
+	Strategy   string `json:"strategy" yaml:"strategy"`
+	Currency   string `json:"currency" yaml:"currency"`
+	// TaxRate is the tax rate in percent.
+	TaxRate      int     `json:"taxRate" yaml:"taxRate"`
+	Price        float64 `json:"price" yaml:"price"`
+	PriceWithTax float64 `json:"priceWithTax" yaml:"priceWithTax"`
+	// The following fields are only set for recurring prices.
+	SetupFee                     float64 `json:"setupFee" yaml:"setupFee"`
+	EffectiveMonthlyPrice        float64 `json:"effectiveMonthlyPrice" yaml:"effectiveMonthlyPrice"`
+	EffectiveMonthlyPriceWithTax float64 `json:"effectiveMonthlyPriceWithTax" yaml:"effectiveMonthlyPriceWithTax"`
+	// Cheapest is true for the lowest effective monthly price of the plan.
+	Cheapest bool `json:"cheapest" yaml:"cheapest"`
+	// Totals and TotalsWithTax hold the total cost over 1, 12 and 24 months, setup fee included.
+	Totals        []float64 `json:"totals" yaml:"totals"`
+	TotalsWithTax []float64 `json:"totalsWithTax" yaml:"totalsWithTax"`
+}
+
+// init registers all flags
+func init() {
+	flag.BindCategoryFlag(Cmd, &category)
+
+	Cmd.PersistentFlags().BoolVar(&withTax, "tax", false, "show prices with tax in the table, json, jsonl, yaml and csv outputs always include both")
+}
+
+// runner is the main function for the prices command
+func runner(cmd *cobra.Command, args []string) error {
+	if flag.IsAllEndpoints(cmd) {
+		return fmt.Errorf("error: --%s %s is not supported by prices", flag.OVHAPIEndpointFlagName, flag.OVHAPIEndpointAll)
+	}
+
+	printer, err := output.NewPrinter(cmd.Flag(flag.OutputFlagName).Value.String(), cmd.Flag(flag.TemplateFileFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	// Initialize kimsufi service
+	k, err := flag.NewService(cmd)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	catalog, err := k.ListServersWithContext(cmd.Context(), cmd.Flag(flag.CountryFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	var plans []kimsuficatalog.Plan
+	for _, planCode := range args {
+		plan := catalog.GetPlan(planCode)
+		if plan == nil {
+			return fmt.Errorf("plan %s not found", planCode)
+		}
+
+		plans = append(plans, *plan)
+	}
+	if len(args) == 0 {
+		for _, plan := range catalog.Plans {
+			// Filter plans by category
+			if category != "" && category != plan.GetCategory() {
+				continue
+			}
+
+			plans = append(plans, plan)
+		}
+	}
+
+	var results []Result
+	for _, plan := range plans {
+		results = append(results, newResults(catalog.Locale, plan)...)
+	}
+
+	if printer.Format == output.FormatTable {
+		printTable(results)
+		return nil
+	}
+
+	err = printer.Print(os.Stdout, results)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	return nil
+}
+
+// newResults returns a result for each price of the plan, in the catalog order,
+// and marks the recurring price with the cheapest effective monthly price.
+func newResults(locale kimsuficatalog.Locale, plan kimsuficatalog.Plan) []Result {
+	var (
+		results  []Result
+		cheapest = -1
+	)
+	for _, price := range plan.Pricings {
+		r := Result{
+			PlanCode:      plan.PlanCode,
+			InvoiceName:   plan.InvoiceName,
+			Phase:         price.Phase,
+			Capacities:    price.Capacities,
+			Interval:      price.Interval,
+			IntervalUnit:  price.IntervalUnit,
+			Mode:          price.Mode,
+			Commitment:    price.Commitement,
+			Strategy:      price.Strategy,
+			Currency:      locale.CurrencyCode,
+			TaxRate:       locale.TaxRate,
+			Price:         price.GetPrice(),
+			PriceWithTax:  locale.WithTax(price.GetPrice()),
+			Totals:        []float64{},
+			TotalsWithTax: []float64{},
+		}
+
+		// Always encode empty lists instead of null.
+		if r.Capacities == nil {
+			r.Capacities = []string{}
+		}
+
+		if price.Interval > 0 {
+			setupFee := plan.GetSetupFeeFor(price)
+
+			r.SetupFee = setupFee.GetPrice()
+			r.EffectiveMonthlyPrice = price.GetEffectiveMonthlyPrice(setupFee)
+			r.EffectiveMonthlyPriceWithTax = locale.WithTax(r.EffectiveMonthlyPrice)
+
+			for _, months := range totalMonths {
+				total := price.GetTotal(setupFee, months)
+				r.Totals = append(r.Totals, total)
+				r.TotalsWithTax = append(r.TotalsWithTax, locale.WithTax(total))
+			}
+
+			if cheapest < 0 || r.EffectiveMonthlyPrice < results[cheapest].EffectiveMonthlyPrice {
+				cheapest = len(results)
+			}
+		}
+
+		results = append(results, r)
+	}
+
+	if cheapest >= 0 {
+		results[cheapest].Cheapest = true
+	}
+
+	return results
+}
+
+// printTable displays the prices as a table, with or without tax.
+func printTable(results []Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+
+	fmt.Fprint(w, "planCode\tphase\tcapacities\tinterval\tmode\tcommitment\tstrategy\tprice\tsetup\teffective monthly") // nolint:errcheck
+	for _, months := range totalMonths {
+		fmt.Fprintf(w, "\ttotal %dm", months) // nolint:errcheck
+	}
+	fmt.Fprintln(w) // nolint:errcheck
+
+	fmt.Fprint(w, "--------\t-----\t----------\t--------\t----\t----------\t--------\t-----\t-----\t-----------------") // nolint:errcheck
+	for _, months := range totalMonths {
+		fmt.Fprintf(w, "\t%s", strings.Repeat("-", len(fmt.Sprintf("total %dm", months)))) // nolint:errcheck
+	}
+	fmt.Fprintln(w) // nolint:errcheck
+
+	for _, r := range results {
+		price, effective, totals := r.Price, r.EffectiveMonthlyPrice, r.Totals
+		if withTax {
+			price, effective, totals = r.PriceWithTax, r.EffectiveMonthlyPriceWithTax, r.TotalsWithTax
+		}
+
+		interval := "once"
+		if r.Interval > 0 {
+			interval = fmt.Sprintf("%d %s", r.Interval, r.IntervalUnit)
+			if r.Interval > 1 {
+				interval += "s"
+			}
+		}
+
+		commitment := "-"
+		if r.Commitment > 0 {
+			commitment = fmt.Sprintf("%d months", r.Commitment)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%.2f %s", r.PlanCode, r.Phase, strings.Join(r.Capacities, ","), interval, r.Mode, commitment, r.Strategy, price, r.Currency) // nolint:errcheck
+
+		if r.Interval == 0 {
+			fmt.Fprint(w, "\t-\t-") // nolint:errcheck
+			for range totalMonths {
+				fmt.Fprint(w, "\t-") // nolint:errcheck
+			}
+			fmt.Fprintln(w) // nolint:errcheck
+			continue
+		}
+
+		cheapest := ""
+		if r.Cheapest {
+			cheapest = " *"
+		}
+
+		fmt.Fprintf(w, "\t%.2f %s\t%.2f %s%s", r.SetupFee, r.Currency, effective, r.Currency, cheapest) // nolint:errcheck
+		for _, total := range totals {
+			fmt.Fprintf(w, "\t%.2f %s", total, r.Currency) // nolint:errcheck
+		}
+		fmt.Fprintln(w) // nolint:errcheck
+	}
+	w.Flush() // nolint:errcheck
+
+	if withTax && len(results) > 0 {
+		fmt.Printf("\nprices include %d%% tax, * marks the cheapest effective monthly price of each plan\n", results[0].TaxRate)
+	} else if len(results) > 0 {
+		fmt.Println("\nprices exclude tax, * marks the cheapest effective monthly price of each plan")
+	}
+}
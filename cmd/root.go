@@ -5,11 +5,17 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/cache"
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/check"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/compare"
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/list"
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/order"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/prices"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/search"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/servefake"
 	"github.com/TheoBrigitte/kimsufi-notifier/cmd/version"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/watch"
 )
 
 // rootCmd represents the base command when called without any arguments
@@ -17,7 +23,7 @@ var rootCmd = &cobra.Command{
 	Use:               "kimsufi-notifier",
 	Short:             "kimsufi availability notifier",
 	Long:              "List, check availability and order OVH Eco (including kimsufi) servers.",
-	PersistentPreRunE: logLevel,
+	PersistentPreRunE: preRun,
 	SilenceUsage:      true,
 }
 
@@ -27,10 +33,16 @@ func init() {
 	flag.Bind(rootCmd)
 
 	// Subcommands
+	rootCmd.AddCommand(cache.Cmd)
 	rootCmd.AddCommand(check.Cmd)
+	rootCmd.AddCommand(compare.Cmd)
 	rootCmd.AddCommand(order.Cmd)
 	rootCmd.AddCommand(list.Cmd)
+	rootCmd.AddCommand(prices.Cmd)
+	rootCmd.AddCommand(search.Cmd)
+	rootCmd.AddCommand(servefake.Cmd)
 	rootCmd.AddCommand(version.Cmd)
+	rootCmd.AddCommand(watch.Cmd)
 }
 
 // Execute is the main entry point for the CLI
@@ -0,0 +1,248 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	pkgcategory "github.com/TheoBrigitte/kimsufi-notifier/pkg/category"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/hardware"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/output"
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:   "search",
+		Short: "Search servers by hardware specifications",
+		Long: `Search servers configurations from OVH Eco (including Kimsufi) catalog by hardware specifications.
+
+Each combination of memory and storage options of a plan is a configuration,
+its specifications are resolved from the catalog products and its price includes the options.`,
+		Example: `  kimsufi-notifier search --min-ram 32 --ecc --disk-tech nvme
+  kimsufi-notifier search --cpu-brand AMD --min-cores 8 --max-price 20 --available
+  kimsufi-notifier search --min-storage-tb 4 --datacenters gra,rbx --category kimsufi`,
+		RunE:        runner,
+		Annotations: map[string]string{flag.ConfigAnnotation: "true", flag.OutputAnnotation: "true"},
+	}
+
+	// Flags variables
+	category      string
+	datacenters   []string
+	humanLevel    int
+	availableOnly bool
+	where         string
+	filter        hardware.Filter
+)
+
+// Result represents a server configuration and its availability,
+// it is the schema of the json, jsonl, yaml and csv outputs.
+type Result struct {
+	PlanCode    string `json:"planCode" yaml:"planCode"`
+	Category    string `json:"category" yaml:"category"`
+	InvoiceName string `json:"invoiceName" yaml:"invoiceName"`
+	Memory      string `json:"memory" yaml:"memory"`
+	Storage     string `json:"storage" yaml:"storage"`
+	CPU         string `json:"cpu" yaml:"cpu"`
+	Cores       int    `json:"cores" yaml:"cores"`
+	// RAM is the memory size in GB.
+	RAM int  `json:"ram" yaml:"ram"`
+	ECC bool `json:"ecc" yaml:"ecc"`
+	// Disks describes the disks (e.g. 2x480GB NVMe).
+	Disks     string  `json:"disks" yaml:"disks"`
+	StorageTB float64 `json:"storageTB" yaml:"storageTB"`
+	// Bandwidth is the bandwidth in Mbit/s.
+	Bandwidth float64 `json:"bandwidth" yaml:"bandwidth"`
+	Price     float64 `json:"price" yaml:"price"`
+	Currency  string  `json:"currency" yaml:"currency"`
+	// Status is either available or unavailable.
+	Status string `json:"status" yaml:"status"`
+	// Datacenters holds the codes of the datacenters where the configuration is available.
+	Datacenters []string `json:"datacenters" yaml:"datacenters"`
+	// DatacenterNames holds the full names of the datacenters where the configuration is available.
+	DatacenterNames []string `json:"datacenterNames" yaml:"datacenterNames"`
+}
+
+// init registers all flags
+func init() {
+	flag.BindCategoryFlag(Cmd, &category)
+	flag.BindDatacentersFlag(Cmd, &datacenters)
+	flag.BindHumanFlag(Cmd, &humanLevel)
+	flag.BindWhereFlag(Cmd, &where)
+
+	Cmd.PersistentFlags().BoolVar(&availableOnly, "available", false, "only show available configurations")
+	Cmd.PersistentFlags().IntVar(&filter.MinRAM, "min-ram", 0, "minimum memory size in GB")
+	Cmd.PersistentFlags().BoolVar(&filter.ECC, "ecc", false, "require ECC memory")
+	Cmd.PersistentFlags().IntVar(&filter.MinCores, "min-cores", 0, "minimum number of CPU cores")
+	Cmd.PersistentFlags().StringVar(&filter.CPUBrand, "cpu-brand", "", "CPU brand (e.g. AMD, Intel)")
+	Cmd.PersistentFlags().StringVar(&filter.DiskTech, "disk-tech", "", fmt.Sprintf("disk technology, at least one disk must match (allowed values: %s)", strings.Join(hardware.DiskTechs, ", ")))
+	Cmd.PersistentFlags().Float64Var(&filter.MinStorageTB, "min-storage-tb", 0, "minimum total raw storage capacity in TB")
+	Cmd.PersistentFlags().Float64Var(&filter.MinBandwidth, "min-bandwidth", 0, "minimum bandwidth in Mbit/s")
+	Cmd.PersistentFlags().Float64Var(&filter.MaxPrice, "max-price", 0, "maximum monthly price, options included")
+}
+
+// runner is the main function for the search command
+func runner(cmd *cobra.Command, args []string) error {
+	err := filter.Validate()
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	whereExpr, err := flag.CompileWhere(where)
+	if err != nil {
+		return err
+	}
+
+	if flag.IsAllEndpoints(cmd) {
+		return fmt.Errorf("error: --%s %s is not supported by search", flag.OVHAPIEndpointFlagName, flag.OVHAPIEndpointAll)
+	}
+
+	printer, err := output.NewPrinter(cmd.Flag(flag.OutputFlagName).Value.String(), cmd.Flag(flag.TemplateFileFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	// Initialize kimsufi service
+	k, err := flag.NewService(cmd)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	// List servers
+	catalog, err := k.ListServersWithContext(cmd.Context(), cmd.Flag(flag.CountryFlagName).Value.String())
+	if err != nil {
+		return fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	// List availabilities
+	availabilities, err := k.GetAvailabilitiesWithContext(cmd.Context(), datacenters, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list availabilities: %w", err)
+	}
+
+	var (
+		results   []Result
+		available bool
+	)
+	for _, plan := range catalog.Plans {
+		// Filter plans by category
+		if category != "" && category != plan.GetCategory() {
+			continue
+		}
+
+		for _, c := range hardware.Configurations(catalog, &plan) {
+			if !filter.Match(c) {
+				continue
+			}
+
+			datacenters := c.Availabilities(*availabilities).GetAvailableDatacenters()
+			if whereExpr != nil && !whereExpr.Match(c.Row(datacenters)) {
+				continue
+			}
+
+			r := newResult(c, datacenters)
+			if r.Status == kimsufiavailability.StatusAvailable {
+				available = true
+			} else if availableOnly {
+				continue
+			}
+
+			results = append(results, r)
+		}
+	}
+
+	// Sort configurations by price
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Price < results[j].Price
+	})
+
+	if printer.Format == output.FormatTable {
+		printTable(results)
+	} else {
+		err = printer.Print(os.Stdout, results)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	if !available {
+		// Nothing available
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// newResult returns the result of the configuration available in the datacenters.
+func newResult(c hardware.Configuration, datacenters kimsufiavailability.Datacenters) Result {
+	t := c.Technical
+
+	var disks []string
+	for _, disk := range t.Storage.Disks {
+		d := fmt.Sprintf("%dx%dGB", disk.Number, disk.Capacity)
+		if tech := hardware.DiskTech(disk); tech != "" {
+			d += " " + tech
+		}
+		disks = append(disks, d)
+	}
+
+	r := Result{
+		PlanCode:        c.PlanCode,
+		Category:        c.Category,
+		InvoiceName:     c.InvoiceName,
+		Memory:          c.Memory,
+		Storage:         c.Storage,
+		CPU:             strings.TrimSpace(t.Server.CPU.Brand + " " + t.Server.CPU.Model),
+		Cores:           c.Cores(),
+		RAM:             t.Memory.Size,
+		ECC:             t.Memory.ECC,
+		Disks:           strings.Join(disks, " + "),
+		StorageTB:       c.StorageTB(),
+		Bandwidth:       t.Bandwidth.Level,
+		Price:           c.Price,
+		Currency:        c.Currency,
+		Status:          datacenters.Status(),
+		Datacenters:     datacenters.Codes(),
+		DatacenterNames: datacenters.ToFullNamesOrCodes(),
+	}
+
+	// Always encode empty lists instead of null.
+	if r.Datacenters == nil {
+		r.Datacenters = []string{}
+		r.DatacenterNames = []string{}
+	}
+
+	return r
+}
+
+// printTable displays the configurations as a table.
+func printTable(results []Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(w, "planCode\tcategory\tcpu\tcores\tram\tdisks\tbandwidth\tprice\tstatus\tdatacenters") // nolint:errcheck
+	fmt.Fprintln(w, "--------\t--------\t---\t-----\t---\t-----\t---------\t-----\t------\t-----------") // nolint:errcheck
+
+	for _, r := range results {
+		categoryDisplay := pkgcategory.GetDisplayName(r.Category)
+		if categoryDisplay == "" {
+			categoryDisplay = r.Category
+		}
+
+		ram := fmt.Sprintf("%dGB", r.RAM)
+		if r.ECC {
+			ram += " ECC"
+		}
+
+		datacenterNames := r.Datacenters
+		if humanLevel > 0 {
+			datacenterNames = r.DatacenterNames
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%gMbps\t%.2f %s\t%s\t%s\n", r.PlanCode, categoryDisplay, r.CPU, r.Cores, ram, r.Disks, r.Bandwidth, r.Price, r.Currency, r.Status, strings.Join(datacenterNames, ", ")) // nolint:errcheck
+	}
+	w.Flush() // nolint:errcheck
+}
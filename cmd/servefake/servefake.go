@@ -0,0 +1,78 @@
+package servefake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/ovhfake"
+)
+
+const (
+	listenDefault = "127.0.0.1:8080"
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:    "serve-fake",
+		Short:  "Serve a fake OVH API",
+		Long:   "Serve a fake OVH API for tests and local development\n\nAvailability and checkout are scripted by a YAML scenario, see pkg/kimsufi/ovhfake.Scenario.\nUse --endpoint with the server URL to target it.",
+		Hidden: true,
+		Example: `  kimsufi-notifier serve-fake --listen 127.0.0.1:8080 --scenario scenario.yaml
+  kimsufi-notifier check --endpoint http://127.0.0.1:8080 --plan-code 24sk10`,
+		RunE: runner,
+	}
+
+	// Flags variables
+	listen       string
+	scenarioFile string
+)
+
+// init registers all flags
+func init() {
+	Cmd.PersistentFlags().StringVar(&listen, "listen", listenDefault, "address to listen on")
+	Cmd.PersistentFlags().StringVar(&scenarioFile, "scenario", "", "YAML scenario file, a default scenario is used when empty")
+}
+
+// runner is the main function for the serve-fake command
+func runner(cmd *cobra.Command, args []string) error {
+	scenario := ovhfake.DefaultScenario()
+	if scenarioFile != "" {
+		var err error
+		scenario, err = ovhfake.LoadScenario(scenarioFile)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           ovhfake.New(scenario),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop serving on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background()) // nolint:errcheck
+	}()
+
+	log.Infof("serving fake OVH API on http://%s", listen)
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/flag"
+	"github.com/TheoBrigitte/kimsufi-notifier/cmd/notify"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	intervalDefault = time.Minute
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Watch server availability",
+		Long:  "Watch OVH Eco (including Kimsufi) server availability\n\nAvailability is polled at the given interval and only changes are reported,\na server configuration is reported when it becomes available or unavailable in a datacenter.",
+		Example: `  kimsufi-notifier watch --plan-code 24ska01
+  kimsufi-notifier watch --plan-code 24ska01 --datacenters gra,rbx --interval 30s`,
+		RunE:        runner,
+		Annotations: map[string]string{flag.ConfigAnnotation: "true"},
+	}
+
+	// Flags variables
+	datacenters []string
+	options     map[string]string
+	planCode    string
+	humanLevel  int
+	interval    time.Duration
+
+	notifyFlags notify.Flags
+)
+
+// init registers all flags
+func init() {
+	flag.BindPlanCodeFlag(Cmd, &planCode)
+	flag.BindDatacentersFlag(Cmd, &datacenters)
+	flag.BindHumanFlag(Cmd, &humanLevel)
+	notify.Bind(Cmd, &notifyFlags)
+
+	Cmd.PersistentFlags().DurationVar(&interval, "interval", intervalDefault, "interval between availability checks")
+	Cmd.PersistentFlags().StringToStringVarP(&options, "option", "o", nil, "options to filter on, comma separated list of key=value (e.g. memory=ram-64g-noecc-2133)")
+}
+
+// summary holds the statistics of a watch session.
+type summary struct {
+	start          time.Time
+	polls          int
+	failedPolls    int
+	nowAvailable   int
+	nowUnavailable int
+}
+
+// runner is the main function for the watch command
+func runner(cmd *cobra.Command, args []string) error {
+	// Initialize kimsufi service
+	k, err := flag.NewPollingService(cmd, interval)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	// Flag validation
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+
+	notifiers, err := notifyFlags.NewRegistry()
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	// Stop watching on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var catalog *kimsuficatalog.Catalog
+	if notifiers.Len() > 0 {
+		// Get the catalog to add plan details to notifications.
+		catalog, err = k.ListServersWithContext(ctx, cmd.Flag(flag.CountryFlagName).Value.String())
+		if err != nil {
+			return fmt.Errorf("failed to list servers: %w", err)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s := summary{
+		start: time.Now(),
+	}
+
+	var previous kimsufiavailability.Availabilities
+	for {
+		current, err := getAvailabilities(ctx, k)
+		if ctx.Err() != nil {
+			// Interrupted while polling, nothing to report for this poll.
+			printSummary(s, previous)
+			return nil
+		}
+
+		s.polls++
+		if err != nil {
+			s.failedPolls++
+			log.Errorf("failed to get availabilities: %v", err)
+		} else {
+			transitions := current.Transitions(previous)
+			for _, t := range transitions {
+				if t.IsAvailable() {
+					s.nowAvailable++
+				} else {
+					s.nowUnavailable++
+				}
+
+				printTransition(t)
+			}
+
+			notify.Send(ctx, notifiers, newEvents(catalog, transitions))
+
+			previous = current
+		}
+
+		select {
+		case <-ctx.Done():
+			printSummary(s, previous)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// getAvailabilities returns the current availabilities.
+// No availabilities found is not considered an error.
+func getAvailabilities(ctx context.Context, k *kimsufi.Service) (kimsufiavailability.Availabilities, error) {
+	availabilities, err := k.GetAvailabilitiesWithContext(ctx, datacenters, planCode, options)
+	if err != nil {
+		if kimsufi.IsAvailabilityNotFoundError(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return *availabilities, nil
+}
+
+// newEvents returns the notification events for the server configurations which became available.
+// Transitions of the same server configuration are grouped into a single event.
+func newEvents(catalog *kimsuficatalog.Catalog, transitions []kimsufiavailability.Transition) []notifier.Event {
+	var availabilities kimsufiavailability.Availabilities
+	for _, t := range transitions {
+		if !t.IsAvailable() {
+			continue
+		}
+
+		datacenter := kimsufiavailability.Datacenter{
+			Datacenter:   t.Datacenter,
+			Availability: t.Current,
+		}
+
+		// Transitions are sorted, the same server configuration can only be the last one.
+		last := len(availabilities) - 1
+		if last >= 0 &&
+			availabilities[last].PlanCode == t.PlanCode &&
+			availabilities[last].Memory == t.Memory &&
+			availabilities[last].Storage == t.Storage {
+			availabilities[last].Datacenters = append(availabilities[last].Datacenters, datacenter)
+			continue
+		}
+
+		a := kimsufiavailability.Availability{
+			PlanCode:    t.PlanCode,
+			Memory:      t.Memory,
+			Storage:     t.Storage,
+			Datacenters: []kimsufiavailability.Datacenter{datacenter},
+		}
+		availabilities = append(availabilities, a)
+	}
+
+	var events []notifier.Event
+	for _, a := range availabilities {
+		events = append(events, notifier.NewEvent(catalog, a.PlanCode, a.Memory, a.Storage, a.Datacenters))
+	}
+
+	return events
+}
+
+// printTransition displays a single availability transition.
+func printTransition(t kimsufiavailability.Transition) {
+	status := kimsufiavailability.StatusUnavailable
+	if t.IsAvailable() {
+		status = kimsufiavailability.StatusAvailable
+	}
+
+	fmt.Printf("> %s %s memory=%s storage=%s datacenter=%s %s (%s -> %s)\n", time.Now().Format(time.RFC3339), t.PlanCode, t.Memory, t.Storage, datacenterName(t.Datacenter), status, t.Previous, t.Current)
+}
+
+// printSummary displays the statistics of the watch session
+// and the server configurations which are still available.
+func printSummary(s summary, current kimsufiavailability.Availabilities) {
+	fmt.Printf("> watched for %s: %d polls (%d failed), %d became available, %d became unavailable\n", time.Since(s.start).Round(time.Second), s.polls, s.failedPolls, s.nowAvailable, s.nowUnavailable)
+
+	for _, a := range current {
+		d := a.GetAvailableDatacenters()
+		if len(d) == 0 {
+			continue
+		}
+
+		var datacenterNames []string
+		for _, code := range d.Codes() {
+			datacenterNames = append(datacenterNames, datacenterName(code))
+		}
+
+		fmt.Printf("> still available: %s memory=%s storage=%s datacenters=%s\n", a.PlanCode, a.Memory, a.Storage, strings.Join(datacenterNames, ","))
+	}
+}
+
+// datacenterName returns the datacenter full name in human mode,
+// or the datacenter code otherwise.
+func datacenterName(code string) string {
+	if humanLevel > 0 {
+		d := kimsufiavailability.Datacenter{Datacenter: code}
+		fullName := d.GetFullName()
+		if fullName != nil {
+			return *fullName
+		}
+	}
+
+	return code
+}
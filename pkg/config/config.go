@@ -0,0 +1,191 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsufiregion "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/region"
+)
+
+const (
+	// DirName is the name of the configuration directory.
+	DirName = "kimsufi-notifier"
+	// FileName is the name of the configuration file.
+	FileName = "config.yaml"
+)
+
+// Config represents the configuration file.
+// All fields are optional, command line flags take precedence over the configuration.
+type Config struct {
+	// Endpoint is the OVH API endpoint, e.g. ovh-eu.
+	Endpoint string `yaml:"endpoint"`
+	// Country is the OVH subsidiary, e.g. FR.
+	Country     string      `yaml:"country"`
+	Credentials Credentials `yaml:"credentials"`
+	Notify      Notify      `yaml:"notify"`
+	Checks      []Check     `yaml:"checks"`
+}
+
+// Credentials holds the names of the environment variables containing the OVH API credentials.
+type Credentials struct {
+	AppKey      string `yaml:"appKey"`
+	AppSecret   string `yaml:"appSecret"`
+	ConsumerKey string `yaml:"consumerKey"`
+}
+
+// Notify holds the notification services base URLs, to target self-hosted instances.
+type Notify struct {
+	TelegramURL string `yaml:"telegramURL"`
+	NtfyURL     string `yaml:"ntfyURL"`
+	GotifyURL   string `yaml:"gotifyURL"`
+}
+
+// Check represents a named availability check.
+type Check struct {
+	Name        string   `yaml:"name"`
+	PlanCodes   []string `yaml:"planCodes"`
+	Datacenters []string `yaml:"datacenters"`
+	// Options are the options to filter on, same as check --option (e.g. memory: ram-64g-noecc-2133).
+	Options map[string]string `yaml:"options"`
+}
+
+// DefaultPath returns the default configuration file path,
+// $XDG_CONFIG_HOME/kimsufi-notifier/config.yaml or ~/.config/kimsufi-notifier/config.yaml.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, DirName, FileName), nil
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+
+	c, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Decode reads and validates a configuration from r.
+// Unknown fields are rejected.
+func Decode(r io.Reader) (*Config, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+
+	var c Config
+	err := d.Decode(&c)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Validate checks the configuration values,
+// all the errors found are returned joined together.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Endpoint != "" && !slices.Contains(kimsufi.GetOVHEndpoints(), c.Endpoint) && !kimsufi.IsURLEndpoint(c.Endpoint) {
+		errs = append(errs, fmt.Errorf("endpoint: unknown endpoint %q (allowed values: %s)", c.Endpoint, strings.Join(kimsufi.GetOVHEndpoints(), ", ")))
+	}
+
+	if c.Country != "" {
+		err := ValidateCountry(c.Endpoint, c.Country)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("country: %w", err))
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, check := range c.Checks {
+		prefix := fmt.Sprintf("checks[%d]", i)
+		if check.Name != "" {
+			prefix = fmt.Sprintf("checks[%d] %q", i, check.Name)
+		}
+
+		if check.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is required", prefix))
+		} else if names[check.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name", prefix))
+		}
+		names[check.Name] = true
+
+		if len(check.PlanCodes) == 0 {
+			errs = append(errs, fmt.Errorf("%s: planCodes is required", prefix))
+		}
+
+		for _, datacenter := range check.Datacenters {
+			if kimsufiavailability.GetDatacenterInfoByCode(datacenter) == nil {
+				errs = append(errs, fmt.Errorf("%s: unknown datacenter %q (known values: %s)", prefix, datacenter, strings.Join(kimsufiavailability.GetDatacentersKnownCodes(), ", ")))
+			}
+		}
+
+		for key := range check.Options {
+			if key == "" {
+				errs = append(errs, fmt.Errorf("%s: option name is required", prefix))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateCountry checks the country is allowed for the endpoint,
+// or for any endpoint when empty, unknown and URL endpoints allow any country.
+// The configuration country must also be validated against the effective endpoint,
+// which can be set on the command line.
+func ValidateCountry(endpoint, country string) error {
+	if endpoint == "" {
+		if kimsufiregion.GetRegionFromCountry(country) == nil {
+			return fmt.Errorf("unknown country %q", country)
+		}
+
+		return nil
+	}
+
+	region := kimsufiregion.GetRegionFromEndpoint(endpoint)
+	if region == nil {
+		// Unknown endpoints are reported separately, URL endpoints allow any country.
+		return nil
+	}
+
+	if region.HasCountry(country) {
+		return nil
+	}
+
+	var countries []string
+	for _, c := range region.Countries {
+		countries = append(countries, c.Code)
+	}
+
+	return fmt.Errorf("country %q is not allowed for endpoint %s (allowed values: %s)", country, endpoint, strings.Join(countries, ", "))
+}
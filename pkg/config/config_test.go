@@ -0,0 +1,166 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecode(t *testing.T) {
+	input := `
+endpoint: ovh-ca
+country: CA
+credentials:
+  appKey: MY_APP_KEY
+notify:
+  ntfyURL: https://ntfy.example.com
+checks:
+  - name: cheap
+    planCodes: [24ska01, 24sk10]
+    datacenters: [bhs]
+    options:
+      memory: ram-32g-noecc-2133
+`
+
+	expected := &Config{
+		Endpoint:    "ovh-ca",
+		Country:     "CA",
+		Credentials: Credentials{AppKey: "MY_APP_KEY"},
+		Notify:      Notify{NtfyURL: "https://ntfy.example.com"},
+		Checks: []Check{
+			{
+				Name:        "cheap",
+				PlanCodes:   []string{"24ska01", "24sk10"},
+				Datacenters: []string{"bhs"},
+				Options:     map[string]string{"memory": "ram-32g-noecc-2133"},
+			},
+		},
+	}
+
+	actual, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("Decode() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "url endpoint",
+			input:    "endpoint: http://127.0.0.1:8080\ncountry: US\n",
+			expected: nil,
+		},
+		{
+			name:     "unknown field",
+			input:    "endpoint: ovh-eu\nplanCode: 24ska01\n",
+			expected: []string{"field planCode not found"},
+		},
+		{
+			name:     "country not in endpoint region",
+			input:    "endpoint: ovh-eu\ncountry: US\n",
+			expected: []string{`country: country "US" is not allowed for endpoint ovh-eu (allowed values: CZ, DE, ES, FI, FR, GB, IE, IT, LT, MA, NL, PL, PT, SN, TN)`},
+		},
+		{
+			name:     "unknown endpoint and country",
+			input:    "endpoint: ovh-xx\ncountry: XX\n",
+			expected: []string{`endpoint: unknown endpoint "ovh-xx"`},
+		},
+		{
+			name: "invalid checks",
+			input: `
+checks:
+  - name: a
+    planCodes: [24ska01]
+    datacenters: [gra, grx]
+  - name: a
+  - planCodes: [24sk10]
+`,
+			expected: []string{
+				`checks[0] "a": unknown datacenter "grx" (known values: `,
+				`checks[1] "a": duplicate name`,
+				`checks[1] "a": planCodes is required`,
+				`checks[2]: name is required`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tc.input))
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error to contain %q, got:\n%v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCountry(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		country  string
+		expected string
+	}{
+		{endpoint: "ovh-eu", country: "fr"},
+		{endpoint: "ovh-ca", country: "FR", expected: `country "FR" is not allowed for endpoint ovh-ca (allowed values: `},
+		{endpoint: "", country: "FR"},
+		{endpoint: "", country: "XX", expected: `unknown country "XX"`},
+		{endpoint: "http://127.0.0.1:8080", country: "XX"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.endpoint+"/"+tc.country, func(t *testing.T) {
+			err := ValidateCountry(tc.endpoint, tc.country)
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error to contain %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/tmp/xdg/kimsufi-notifier/config.yaml"
+	if path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
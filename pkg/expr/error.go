@@ -0,0 +1,32 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned when an expression cannot be compiled,
+// it points at the offending column of the expression.
+type SyntaxError struct {
+	Input string
+	// Column is the 1-based column of the error in the expression.
+	Column  int
+	Message string
+}
+
+func newSyntaxError(input string, pos int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{
+		Input:   input,
+		Column:  pos + 1,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+// Pointer returns the expression and a caret under the offending column on the next line.
+func (e *SyntaxError) Pointer() string {
+	return e.Input + "\n" + strings.Repeat(" ", e.Column-1) + "^"
+}
@@ -0,0 +1,487 @@
+// Package expr implements a small boolean expression language to filter rows of variables,
+// for example:
+//
+//	ram >= 32 && ecc && price < 15 && dc in ["gra", "rbx"]
+//
+// Expressions are made of numbers, strings ("..." or '...'), true and false, lists of numbers or strings ([...]),
+// variables, parentheses and the operators !, &&, ||, ==, !=, <, <=, >, >=, in and not in.
+// Strings are compared case insensitively, < <= > >= only apply to numbers.
+// "x in list" is true when x is in the list, or when x is itself a list sharing at least one element with the list.
+//
+// Expressions are type checked against the declared variables when compiled,
+// errors are reported as SyntaxError pointing at the offending column.
+package expr
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Type is the type of a variable or of a sub expression.
+type Type int
+
+const (
+	TypeBool Type = iota + 1
+	TypeNumber
+	TypeString
+	TypeNumberList
+	TypeStringList
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeNumberList:
+		return "list of numbers"
+	case TypeStringList:
+		return "list of strings"
+	default:
+		return "unknown"
+	}
+}
+
+// element returns the type of the elements of a list type, or 0 if t is not a list.
+func (t Type) element() Type {
+	switch t {
+	case TypeNumberList:
+		return TypeNumber
+	case TypeStringList:
+		return TypeString
+	default:
+		return 0
+	}
+}
+
+// Vars declares the variables available to an expression and their type.
+type Vars map[string]Type
+
+// Row holds the values of the variables, by name.
+// Values are bool, float64 (or int), string, []float64 or []string, according to the variable type.
+type Row map[string]any
+
+// Expr is a compiled expression.
+type Expr struct {
+	input string
+	root  *node
+}
+
+// node is a node of the expression tree, it is a literal (value), a variable (name)
+// or an operator (op) applied to its children.
+type node struct {
+	op       string
+	name     string
+	value    any
+	typ      Type
+	pos      int
+	children []*node
+}
+
+// Compile parses and type checks the expression against the variables,
+// the expression must evaluate to a bool.
+func Compile(input string, vars Vars) (*Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens, vars: vars}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t)
+	}
+
+	if root.typ != TypeBool {
+		return nil, newSyntaxError(input, root.pos, "expression must be a bool, got %s", root.typ)
+	}
+
+	return &Expr{input: input, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.input
+}
+
+// Match evaluates the expression against the row.
+// Missing variables evaluate to their zero value.
+func (e *Expr) Match(row Row) bool {
+	v, _ := e.root.eval(row).(bool)
+	return v
+}
+
+// parser is a recursive descent parser, type checking the nodes as they are built.
+type parser struct {
+	input  string
+	tokens []token
+	vars   Vars
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+
+	return t
+}
+
+// accept consumes the next token if it is the operator or keyword op.
+func (p *parser) accept(op string) bool {
+	t := p.peek()
+	if (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == op {
+		p.i++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return newSyntaxError(p.input, t.pos, "unexpected end of expression")
+	}
+
+	return newSyntaxError(p.input, t.pos, "unexpected %q", t.text)
+}
+
+// parseOr parses: and ("||" and)*
+func (p *parser) parseOr() (*node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		pos := p.peek().pos
+		if !p.accept("||") {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left, err = p.logical("||", pos, left, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseAnd parses: not ("&&" not)*
+func (p *parser) parseAnd() (*node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		pos := p.peek().pos
+		if !p.accept("&&") {
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left, err = p.logical("&&", pos, left, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) logical(op string, pos int, left, right *node) (*node, error) {
+	for _, n := range []*node{left, right} {
+		if n.typ != TypeBool {
+			return nil, newSyntaxError(p.input, n.pos, "%s requires bool operands, got %s", op, n.typ)
+		}
+	}
+
+	return &node{op: op, typ: TypeBool, pos: pos, children: []*node{left, right}}, nil
+}
+
+// parseNot parses: "!" not | comparison
+func (p *parser) parseNot() (*node, error) {
+	pos := p.peek().pos
+	if !p.accept("!") {
+		return p.parseComparison()
+	}
+
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	if operand.typ != TypeBool {
+		return nil, newSyntaxError(p.input, operand.pos, "! requires a bool operand, got %s", operand.typ)
+	}
+
+	return &node{op: "!", typ: TypeBool, pos: pos, children: []*node{operand}}, nil
+}
+
+// parseComparison parses: primary (("==" | "!=" | "<" | "<=" | ">" | ">=" | "in" | "not" "in") primary)?
+func (p *parser) parseComparison() (*node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	var op string
+	switch {
+	case t.kind == tokenOperator && slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, t.text):
+		op = t.text
+		p.next()
+	case t.kind == tokenIdent && t.text == "in":
+		op = "in"
+		p.next()
+	case t.kind == tokenIdent && t.text == "not":
+		p.next()
+		if !p.accept("in") {
+			return nil, p.unexpected(p.peek())
+		}
+		op = "not in"
+	default:
+		return left, nil
+	}
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "==", "!=":
+		if left.typ != right.typ || left.typ.element() != 0 {
+			return nil, newSyntaxError(p.input, right.pos, "cannot compare %s %s %s", left.typ, op, right.typ)
+		}
+	case "<", "<=", ">", ">=":
+		if left.typ != TypeNumber {
+			return nil, newSyntaxError(p.input, left.pos, "%s requires numbers, got %s", op, left.typ)
+		}
+		if right.typ != TypeNumber {
+			return nil, newSyntaxError(p.input, right.pos, "%s requires numbers, got %s", op, right.typ)
+		}
+	case "in", "not in":
+		element := right.typ.element()
+		if element == 0 {
+			return nil, newSyntaxError(p.input, right.pos, "%s requires a list, got %s", op, right.typ)
+		}
+		if left.typ != element && left.typ != right.typ {
+			return nil, newSyntaxError(p.input, left.pos, "cannot look for %s in %s", left.typ, right.typ)
+		}
+	}
+
+	return &node{op: op, typ: TypeBool, pos: t.pos, children: []*node{left, right}}, nil
+}
+
+// parsePrimary parses: number | string | "true" | "false" | variable | "(" or ")" | "[" (literal ("," literal)*)? "]"
+func (p *parser) parsePrimary() (*node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		return &node{value: t.value, typ: TypeNumber, pos: t.pos}, nil
+	case tokenString:
+		return &node{value: t.value, typ: TypeString, pos: t.pos}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return &node{value: t.text == "true", typ: TypeBool, pos: t.pos}, nil
+		case "in", "not":
+			return nil, p.unexpected(t)
+		}
+
+		typ, ok := p.vars[t.text]
+		if !ok {
+			names := slices.Sorted(maps.Keys(p.vars))
+			return nil, newSyntaxError(p.input, t.pos, "unknown variable %q (known variables: %s)", t.text, strings.Join(names, ", "))
+		}
+
+		return &node{name: t.text, typ: typ, pos: t.pos}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, newSyntaxError(p.input, p.peek().pos, "missing closing parenthesis")
+			}
+			return n, nil
+		case "[":
+			return p.parseList(t)
+		}
+	}
+
+	return nil, p.unexpected(t)
+}
+
+// parseList parses the elements of a list literal, after the opening bracket.
+// The elements must be literals of the same type, numbers or strings.
+func (p *parser) parseList(open token) (*node, error) {
+	var (
+		element Type
+		values  []any
+	)
+	for !p.accept("]") {
+		if len(values) > 0 && !p.accept(",") {
+			return nil, p.unexpected(p.peek())
+		}
+
+		t := p.next()
+		var typ Type
+		switch t.kind {
+		case tokenNumber:
+			typ = TypeNumber
+		case tokenString:
+			typ = TypeString
+		default:
+			return nil, newSyntaxError(p.input, t.pos, "list elements must be numbers or strings")
+		}
+
+		if element != 0 && typ != element {
+			return nil, newSyntaxError(p.input, t.pos, "list elements must all be of the same type")
+		}
+		element = typ
+		values = append(values, t.value)
+	}
+
+	if len(values) == 0 {
+		return nil, newSyntaxError(p.input, open.pos, "empty list")
+	}
+
+	if element == TypeNumber {
+		numbers := make([]float64, 0, len(values))
+		for _, v := range values {
+			numbers = append(numbers, v.(float64))
+		}
+		return &node{value: numbers, typ: TypeNumberList, pos: open.pos}, nil
+	}
+
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, v.(string))
+	}
+	return &node{value: strs, typ: TypeStringList, pos: open.pos}, nil
+}
+
+// eval returns the value of the node for the row.
+func (n *node) eval(row Row) any {
+	switch {
+	case n.name != "":
+		return normalize(n.typ, row[n.name])
+	case n.op == "":
+		return n.value
+	}
+
+	switch n.op {
+	case "!":
+		return !n.children[0].eval(row).(bool)
+	case "&&":
+		return n.children[0].eval(row).(bool) && n.children[1].eval(row).(bool)
+	case "||":
+		return n.children[0].eval(row).(bool) || n.children[1].eval(row).(bool)
+	}
+
+	left, right := n.children[0].eval(row), n.children[1].eval(row)
+	switch n.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	case "<":
+		return left.(float64) < right.(float64)
+	case "<=":
+		return left.(float64) <= right.(float64)
+	case ">":
+		return left.(float64) > right.(float64)
+	case ">=":
+		return left.(float64) >= right.(float64)
+	case "in":
+		return in(left, right)
+	case "not in":
+		return !in(left, right)
+	}
+
+	return false
+}
+
+// normalize converts a row value to the representation of its type,
+// missing values and values of the wrong type are converted to the zero value.
+func normalize(typ Type, v any) any {
+	switch typ {
+	case TypeBool:
+		b, _ := v.(bool)
+		return b
+	case TypeNumber:
+		switch n := v.(type) {
+		case float64:
+			return n
+		case int:
+			return float64(n)
+		}
+		return float64(0)
+	case TypeString:
+		s, _ := v.(string)
+		return s
+	case TypeNumberList:
+		l, _ := v.([]float64)
+		return l
+	case TypeStringList:
+		l, _ := v.([]string)
+		return l
+	}
+
+	return nil
+}
+
+// equal compares two scalar values, strings are compared case insensitively.
+func equal(a, b any) bool {
+	if s, ok := a.(string); ok {
+		return strings.EqualFold(s, b.(string))
+	}
+
+	return a == b
+}
+
+// in returns true if the value is in the list,
+// or for a list value, if at least one of its elements is in the list.
+func in(value, list any) bool {
+	switch l := list.(type) {
+	case []float64:
+		if values, ok := value.([]float64); ok {
+			return slices.ContainsFunc(values, func(v float64) bool { return slices.Contains(l, v) })
+		}
+		return slices.Contains(l, value.(float64))
+	case []string:
+		contains := func(v string) bool {
+			return slices.ContainsFunc(l, func(s string) bool { return strings.EqualFold(s, v) })
+		}
+		if values, ok := value.([]string); ok {
+			return slices.ContainsFunc(values, contains)
+		}
+		return contains(value.(string))
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testVars = Vars{
+	"ram":   TypeNumber,
+	"ecc":   TypeBool,
+	"price": TypeNumber,
+	"brand": TypeString,
+	"dc":    TypeStringList,
+	"cores": TypeNumber,
+}
+
+var testRow = Row{
+	"ram":   32,
+	"ecc":   true,
+	"price": 14.99,
+	"brand": "AMD",
+	"dc":    []string{"gra", "rbx"},
+	"cores": float64(8),
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: `ram >= 32 && ecc && price < 15 && dc in ["gra","rbx"]`, expected: true},
+		{expression: `ram > 32`, expected: false},
+		{expression: `ram == 32 && cores != 4 && price <= 14.99`, expected: true},
+		{expression: `!ecc || ram < 16`, expected: false},
+		{expression: `!(ecc && ram < 16)`, expected: true},
+		{expression: `ecc == false`, expected: false},
+		{expression: `brand == "amd"`, expected: true},
+		{expression: `brand == 'Intel'`, expected: false},
+		{expression: `brand in ["intel", "amd"]`, expected: true},
+		{expression: `brand not in ["intel"]`, expected: true},
+		{expression: `"GRA" in dc`, expected: true},
+		{expression: `dc in ["bhs", "sbg"]`, expected: false},
+		{expression: `dc not in ["bhs"]`, expected: true},
+		{expression: `cores in [4, 8]`, expected: true},
+		{expression: `false || true && ram >= 32`, expected: true},
+		{expression: `missing_is_zero == 0 || true`, expected: true},
+	}
+
+	vars := Vars{"missing_is_zero": TypeNumber}
+	for k, v := range testVars {
+		vars[k] = v
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			e, err := Compile(tc.expression, vars)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := e.Match(testRow); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	testCases := []struct {
+		expression string
+		expected   SyntaxError
+	}{
+		{
+			expression: `ram >= `,
+			expected:   SyntaxError{Column: 8, Message: "unexpected end of expression"},
+		},
+		{
+			expression: `ram >= 32 && memory > 4`,
+			expected:   SyntaxError{Column: 14, Message: `unknown variable "memory" (known variables: brand, cores, dc, ecc, price, ram)`},
+		},
+		{
+			expression: `ram >= 32 & ecc`,
+			expected:   SyntaxError{Column: 11, Message: `unexpected character '&'`},
+		},
+		{
+			expression: `brand == "amd`,
+			expected:   SyntaxError{Column: 10, Message: "unterminated string"},
+		},
+		{
+			expression: `ram`,
+			expected:   SyntaxError{Column: 1, Message: "expression must be a bool, got number"},
+		},
+		{
+			expression: `ecc && brand`,
+			expected:   SyntaxError{Column: 8, Message: "&& requires bool operands, got string"},
+		},
+		{
+			expression: `brand > 4`,
+			expected:   SyntaxError{Column: 1, Message: "> requires numbers, got string"},
+		},
+		{
+			expression: `ram == "32"`,
+			expected:   SyntaxError{Column: 8, Message: "cannot compare number == string"},
+		},
+		{
+			expression: `dc in "gra"`,
+			expected:   SyntaxError{Column: 7, Message: "in requires a list, got string"},
+		},
+		{
+			expression: `ram in ["a"]`,
+			expected:   SyntaxError{Column: 1, Message: "cannot look for number in list of strings"},
+		},
+		{
+			expression: `dc in ["gra", 1]`,
+			expected:   SyntaxError{Column: 15, Message: "list elements must all be of the same type"},
+		},
+		{
+			expression: `(ecc || ram > 4`,
+			expected:   SyntaxError{Column: 16, Message: "missing closing parenthesis"},
+		},
+		{
+			expression: `ecc ecc`,
+			expected:   SyntaxError{Column: 5, Message: `unexpected "ecc"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := Compile(tc.expression, testVars)
+
+			var got *SyntaxError
+			if !errors.As(err, &got) {
+				t.Fatalf("expected a SyntaxError, got %v", err)
+			}
+
+			tc.expected.Input = tc.expression
+			if diff := cmp.Diff(tc.expected, *got); diff != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorPointer(t *testing.T) {
+	_, err := Compile(`ram >= 32 && price < "15"`, testVars)
+
+	var e *SyntaxError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected a SyntaxError, got %v", err)
+	}
+
+	expected := "ram >= 32 && price < \"15\"\n                     ^"
+	if diff := cmp.Diff(expected, e.Pointer()); diff != "" {
+		t.Errorf("Pointer() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,108 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+// token is a lexical token of an expression, pos is its byte offset in the expression.
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+	pos   int
+}
+
+// operators are the operators and punctuation of the language, longest first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+// lex splits the expression into tokens, the last one is always tokenEOF.
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		r := rune(input[i])
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(input) && input[end] != input[i] {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, newSyntaxError(input, i, "unterminated string")
+			}
+
+			text := input[i : end+1]
+			value := text[1 : len(text)-1]
+			if r == '"' {
+				var err error
+				value, err = strconv.Unquote(text)
+				if err != nil {
+					return nil, newSyntaxError(input, i, "invalid string %s", text)
+				}
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: text, value: value, pos: i})
+			i = end + 1
+
+		case unicode.IsDigit(r) || r == '.':
+			end := i
+			for end < len(input) && (unicode.IsDigit(rune(input[end])) || input[end] == '.') {
+				end++
+			}
+
+			text := input[i:end]
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, newSyntaxError(input, i, "invalid number %s", text)
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: i})
+			i = end
+
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(input) && (unicode.IsLetter(rune(input[end])) || unicode.IsDigit(rune(input[end])) || input[end] == '_') {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: input[i:end], pos: i})
+			i = end
+
+		default:
+			var op string
+			for _, o := range operators {
+				if strings.HasPrefix(input[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, newSyntaxError(input, i, "unexpected character %q", r)
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
@@ -48,3 +48,95 @@ func (a Availabilities) GetAvailableDatacenters() Datacenters {
 
 	return uniqDatacenters
 }
+
+// transitionKey identifies a server configuration in a datacenter.
+type transitionKey struct {
+	planCode   string
+	memory     string
+	storage    string
+	datacenter string
+}
+
+// statuses returns the availability status of each server configuration in each datacenter.
+func (a Availabilities) statuses() map[transitionKey]string {
+	statuses := make(map[transitionKey]string)
+
+	for _, availability := range a {
+		for _, datacenter := range availability.Datacenters {
+			key := transitionKey{
+				planCode:   availability.PlanCode,
+				memory:     availability.Memory,
+				storage:    availability.Storage,
+				datacenter: datacenter.Datacenter,
+			}
+			statuses[key] = datacenter.Availability
+		}
+	}
+
+	return statuses
+}
+
+// Transitions returns the server configurations which flipped between
+// available and unavailable since the previous availabilities.
+// Configurations missing from either side are considered unavailable.
+// Transitions are sorted by plan code, memory, storage and datacenter.
+func (a Availabilities) Transitions(previous Availabilities) []Transition {
+	previousStatuses := previous.statuses()
+	currentStatuses := a.statuses()
+
+	keys := make(map[transitionKey]struct{})
+	for key := range previousStatuses {
+		keys[key] = struct{}{}
+	}
+	for key := range currentStatuses {
+		keys[key] = struct{}{}
+	}
+
+	var transitions []Transition
+	for key := range keys {
+		previousStatus, found := previousStatuses[key]
+		if !found {
+			previousStatus = StatusUnavailable
+		}
+
+		currentStatus, found := currentStatuses[key]
+		if !found {
+			currentStatus = StatusUnavailable
+		}
+
+		previousDatacenter := Datacenter{Datacenter: key.datacenter, Availability: previousStatus}
+		currentDatacenter := Datacenter{Datacenter: key.datacenter, Availability: currentStatus}
+		if previousDatacenter.IsAvailable() == currentDatacenter.IsAvailable() {
+			continue
+		}
+
+		t := Transition{
+			PlanCode:   key.planCode,
+			Memory:     key.memory,
+			Storage:    key.storage,
+			Datacenter: key.datacenter,
+			Previous:   previousStatus,
+			Current:    currentStatus,
+		}
+		transitions = append(transitions, t)
+	}
+
+	slices.SortFunc(transitions, func(i, j Transition) int {
+		return strings.Compare(
+			strings.Join([]string{i.PlanCode, i.Memory, i.Storage, i.Datacenter}, " "),
+			strings.Join([]string{j.PlanCode, j.Memory, j.Storage, j.Datacenter}, " "),
+		)
+	})
+
+	return transitions
+}
+
+// IsAvailable returns true if the server configuration became available.
+func (t Transition) IsAvailable() bool {
+	d := Datacenter{
+		Datacenter:   t.Datacenter,
+		Availability: t.Current,
+	}
+
+	return d.IsAvailable()
+}
@@ -122,3 +122,113 @@ func TestGetAvailableDatacenters(t *testing.T) {
 		})
 	}
 }
+
+func TestTransitions(t *testing.T) {
+	newAvailabilities := func(datacenters ...Datacenter) Availabilities {
+		return Availabilities{
+			{
+				PlanCode:    "24ska01",
+				Memory:      "ram-32g-noecc-2133",
+				Storage:     "softraid-2x2000sa",
+				Datacenters: datacenters,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		previous Availabilities
+		current  Availabilities
+		want     []Transition
+	}{
+		{
+			name:     "empty",
+			previous: nil,
+			current:  nil,
+			want:     nil,
+		},
+		{
+			name:     "first snapshot",
+			previous: nil,
+			current: newAvailabilities(
+				Datacenter{Datacenter: "gra", Availability: "1H-low"},
+				Datacenter{Datacenter: "rbx", Availability: StatusUnavailable},
+			),
+			want: []Transition{
+				{
+					PlanCode:   "24ska01",
+					Memory:     "ram-32g-noecc-2133",
+					Storage:    "softraid-2x2000sa",
+					Datacenter: "gra",
+					Previous:   StatusUnavailable,
+					Current:    "1H-low",
+				},
+			},
+		},
+		{
+			name: "unchanged",
+			previous: newAvailabilities(
+				Datacenter{Datacenter: "gra", Availability: "1H-low"},
+			),
+			current: newAvailabilities(
+				Datacenter{Datacenter: "gra", Availability: "1H-high"},
+			),
+			want: nil,
+		},
+		{
+			name: "flips",
+			previous: newAvailabilities(
+				Datacenter{Datacenter: "gra", Availability: StatusUnavailable},
+				Datacenter{Datacenter: "rbx", Availability: "72H"},
+			),
+			current: newAvailabilities(
+				Datacenter{Datacenter: "gra", Availability: "1H-low"},
+				Datacenter{Datacenter: "rbx", Availability: StatusUnavailable},
+			),
+			want: []Transition{
+				{
+					PlanCode:   "24ska01",
+					Memory:     "ram-32g-noecc-2133",
+					Storage:    "softraid-2x2000sa",
+					Datacenter: "gra",
+					Previous:   StatusUnavailable,
+					Current:    "1H-low",
+				},
+				{
+					PlanCode:   "24ska01",
+					Memory:     "ram-32g-noecc-2133",
+					Storage:    "softraid-2x2000sa",
+					Datacenter: "rbx",
+					Previous:   "72H",
+					Current:    StatusUnavailable,
+				},
+			},
+		},
+		{
+			name: "disappeared",
+			previous: newAvailabilities(
+				Datacenter{Datacenter: "gra", Availability: "1H-low"},
+			),
+			current: nil,
+			want: []Transition{
+				{
+					PlanCode:   "24ska01",
+					Memory:     "ram-32g-noecc-2133",
+					Storage:    "softraid-2x2000sa",
+					Datacenter: "gra",
+					Previous:   "1H-low",
+					Current:    StatusUnavailable,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.current.Transitions(tc.previous)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Transitions() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -12,3 +12,14 @@ type Availability struct {
 	Storage     string       `json:"storage"`
 	Datacenters []Datacenter `json:"datacenters"`
 }
+
+// Transition represents a change of availability for a server configuration
+// (plan code, memory and storage) in a given datacenter.
+type Transition struct {
+	PlanCode   string `json:"planCode"`
+	Memory     string `json:"memory"`
+	Storage    string `json:"storage"`
+	Datacenter string `json:"datacenter"`
+	Previous   string `json:"previous"`
+	Current    string `json:"current"`
+}
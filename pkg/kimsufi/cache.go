@@ -0,0 +1,163 @@
+package kimsufi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	// validatorKeySuffix is appended to the cache key of the entries kept to revalidate expired responses.
+	validatorKeySuffix = "#validator"
+)
+
+// CacheStats holds the counters of the Service cache.
+// The Service cache is an in-memory cache for library users,
+// the CLI does not use it and caches the responses on disk instead (see the diskcache package).
+type CacheStats struct {
+	// Hits is the number of responses served from the cache, including cached errors.
+	Hits uint64
+	// Misses is the number of responses fetched from the API.
+	Misses uint64
+	// Revalidated is the number of misses answered with 304 Not Modified to a conditional request.
+	Revalidated uint64
+}
+
+// cacheStats holds the counters shared by a Service and its authenticated copies.
+type cacheStats struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	revalidated atomic.Uint64
+}
+
+// cacheEntry is a cached response, stored as the serialized body
+// so that callers mutating their results do not alter the cache.
+type cacheEntry struct {
+	body         []byte
+	etag         string
+	lastModified string
+	err          error
+}
+
+// CacheStats returns the counters of the Service cache.
+func (s *Service) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:        s.stats.hits.Load(),
+		Misses:      s.stats.misses.Load(),
+		Revalidated: s.stats.revalidated.Load(),
+	}
+}
+
+// fetch performs a GET request and returns the response as a cache entry.
+// When the Client implements Requester, the previous response is revalidated
+// with If-None-Match or If-Modified-Since when the API returned an ETag or Last-Modified header.
+func (s *Service) fetch(ctx context.Context, path, cacheKey string, needAuth bool) (*cacheEntry, error) {
+	r, ok := s.client.(Requester)
+	if !ok {
+		var body json.RawMessage
+		err := s.client.CallAPIWithContext(ctx, http.MethodGet, path, nil, &body, needAuth)
+		if err != nil {
+			return nil, err
+		}
+
+		return &cacheEntry{body: body}, nil
+	}
+
+	req, err := r.NewRequest(http.MethodGet, path, nil, needAuth)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	var validator *cacheEntry
+	if v, found := s.cache.Get(cacheKey + validatorKeySuffix); found {
+		validator = v.(*cacheEntry)
+		if validator.etag != "" {
+			req.Header.Set("If-None-Match", validator.etag)
+		}
+		if validator.lastModified != "" {
+			req.Header.Set("If-Modified-Since", validator.lastModified)
+		}
+	}
+
+	resp, err := r.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && validator != nil {
+		resp.Body.Close() // nolint:errcheck
+		s.logger.Tracef("cache revalidated: %s", cacheKey)
+		s.stats.revalidated.Add(1)
+		return validator, nil
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		// Build the API error
+		return nil, r.UnmarshalResponse(resp, nil)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+
+	e := &cacheEntry{
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	return e, nil
+}
+
+// store caches the entry with the default expiration of the cache,
+// and keeps its validators for one more expiration period to revalidate it once it expires.
+func (s *Service) store(cacheKey string, e *cacheEntry) {
+	s.cache.Set(cacheKey, e, cache.DefaultExpiration)
+
+	if e.etag == "" && e.lastModified == "" {
+		return
+	}
+
+	_, expiration, found := s.cache.GetWithExpiration(cacheKey)
+	if !found {
+		return
+	}
+
+	ttl := cache.NoExpiration
+	if !expiration.IsZero() {
+		ttl = 2 * time.Until(expiration)
+	}
+	s.cache.Set(cacheKey+validatorKeySuffix, e, ttl)
+}
+
+// decode returns the cached error or unmarshals the cached body into response,
+// the same way ovh.Client does.
+func (e *cacheEntry) decode(response any) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if len(e.body) == 0 {
+		return nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(e.body))
+	d.UseNumber()
+
+	err := d.Decode(response)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
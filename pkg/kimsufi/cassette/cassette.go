@@ -0,0 +1,222 @@
+// Package cassette records OVH API interactions to a cassette file and replays them,
+// to reproduce bug reports and run golden tests without the network.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	redacted = "REDACTED"
+)
+
+var (
+	// RedactedHeaders are the request headers holding credentials, their values are not recorded.
+	RedactedHeaders = []string{
+		"X-Ovh-Application",
+		"X-Ovh-Consumer",
+		"X-Ovh-Signature",
+		"Authorization",
+	}
+)
+
+// Cassette holds the recorded interactions, in the order they happened.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+// URI is the request path and query, the endpoint host is not recorded.
+type Request struct {
+	Method string      `json:"method"`
+	URI    string      `json:"uri"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette file.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Recorder is an http.RoundTripper recording the interactions to a cassette file.
+// The file is written after every interaction, so the cassette is complete even when the program exits abruptly.
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder writing to path.
+// transport is optional, if nil http.DefaultTransport is used.
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Recorder{
+		path:      path,
+		transport: transport,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close() // nolint:errcheck
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	i := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URI:    req.URL.RequestURI(),
+			Header: redactHeader(req.Header),
+			Body:   string(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	err = r.cassette.Save(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper replaying the interactions of a cassette, without the network.
+// Requests are matched on their method and URI, each interaction is replayed once in the recorded order,
+// so repeated requests get the successive recorded responses.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer returns a Replayer for the cassette.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+// It returns an error when no interaction is left for the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close() // nolint:errcheck
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uri := req.URL.RequestURI()
+	for index, i := range r.cassette.Interactions {
+		if r.used[index] || i.Request.Method != req.Method || i.Request.URI != uri {
+			continue
+		}
+		r.used[index] = true
+
+		resp := &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}
+		if resp.Header == nil {
+			resp.Header = make(http.Header)
+		}
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("cassette: no interaction left for %s %s", req.Method, uri)
+}
+
+// redactHeader returns a copy of the header without the credentials values.
+func redactHeader(header http.Header) http.Header {
+	h := header.Clone()
+	for _, name := range RedactedHeaders {
+		if h.Get(name) != "" {
+			h.Set(name, redacted)
+		}
+	}
+
+	return h
+}
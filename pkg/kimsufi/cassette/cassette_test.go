@@ -0,0 +1,90 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordReplay(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, strings.Repeat("a", polls)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recordClient := &http.Client{Transport: NewRecorder(path, nil)}
+
+	var recorded []string
+	for range 2 {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/availabilities?planCode=24ska01", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Ovh-Application", "my-app-key")
+		req.Header.Set("X-Ovh-Timestamp", "1700000000")
+
+		recorded = append(recorded, doRequest(t, recordClient, req))
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	header := c.Interactions[0].Request.Header
+	if header.Get("X-Ovh-Application") != redacted {
+		t.Errorf("expected X-Ovh-Application to be redacted, got %q", header.Get("X-Ovh-Application"))
+	}
+	if header.Get("X-Ovh-Timestamp") != "1700000000" {
+		t.Errorf("expected X-Ovh-Timestamp to be recorded, got %q", header.Get("X-Ovh-Timestamp"))
+	}
+
+	// Replay on another host, interactions are replayed in order
+	replayClient := &http.Client{Transport: NewReplayer(c)}
+
+	var replayed []string
+	for range 2 {
+		req, err := http.NewRequest(http.MethodGet, "http://replay.invalid/availabilities?planCode=24ska01", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		replayed = append(replayed, doRequest(t, replayClient, req))
+	}
+
+	if diff := cmp.Diff(recorded, replayed); diff != "" {
+		t.Errorf("replayed responses mismatch (-want +got):\n%s", diff)
+	}
+
+	// All interactions are used
+	_, err = replayClient.Get("http://replay.invalid/availabilities?planCode=24ska01")
+	if err == nil || !strings.Contains(err.Error(), "no interaction left for GET /availabilities?planCode=24ska01") {
+		t.Errorf("expected no interaction left error, got %v", err)
+	}
+}
+
+func doRequest(t *testing.T, client *http.Client, req *http.Request) string {
+	t.Helper()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.Status + " " + string(body)
+}
@@ -11,6 +11,11 @@ func (c Catalog) GetPlan(planCode string) *Plan {
 	return nil
 }
 
+// WithTax returns the price with the locale tax rate applied.
+func (l Locale) WithTax(price float64) float64 {
+	return price * (1 + float64(l.TaxRate)/100)
+}
+
 // GetProduct returns the product with the given product name.
 func (c Catalog) GetProduct(productName string) *Product {
 	for _, product := range c.Products {
@@ -21,3 +26,20 @@ func (c Catalog) GetProduct(productName string) *Product {
 
 	return nil
 }
+
+// GetAddon returns the addon with the given plan code.
+func (c Catalog) GetAddon(planCode string) *Addon {
+	for _, addon := range c.Addons {
+		if addon.PlanCode == planCode {
+			return &addon
+		}
+	}
+
+	return nil
+}
+
+// GetFirstPrice does best effort to return the first price of the addon,
+// using the same criteria as Plan.GetFirstPrice.
+func (a Addon) GetFirstPrice() PlanPricing {
+	return firstPrice(a.Pricings)
+}
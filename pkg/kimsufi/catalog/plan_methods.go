@@ -88,6 +88,53 @@ func (p *Plan) GetPrices(minInterval int) []PlanPricing {
 	return prices
 }
 
+// GetSetupFee returns the setup fee of the plan, the price with a 0 interval (e.g. installation),
+// or an empty PlanPricing if the plan has none.
+func (p Plan) GetSetupFee() PlanPricing {
+	for _, price := range p.Pricings {
+		if price.Interval == 0 {
+			return price
+		}
+	}
+
+	return PlanPricing{}
+}
+
+// GetSetupFeeFor returns the setup fee applying with the recurring price:
+// the price with a 0 interval and the same mode, or else the same commitment, or else GetSetupFee.
+func (p Plan) GetSetupFeeFor(recurring PlanPricing) PlanPricing {
+	for _, price := range p.Pricings {
+		if price.Interval == 0 && price.Mode == recurring.Mode {
+			return price
+		}
+	}
+
+	for _, price := range p.Pricings {
+		if price.Interval == 0 && price.Commitement == recurring.Commitement {
+			return price
+		}
+	}
+
+	return p.GetSetupFee()
+}
+
+// GetCommitmentPrices returns the recurring prices with a commitment (e.g. degressivity or upfront payment),
+// sorted by commitment.
+func (p Plan) GetCommitmentPrices() []PlanPricing {
+	var prices []PlanPricing
+	for _, price := range p.Pricings {
+		if price.Interval > 0 && price.Commitement > 0 {
+			prices = append(prices, price)
+		}
+	}
+
+	slices.SortStableFunc(prices, func(a, b PlanPricing) int {
+		return a.Commitement - b.Commitement
+	})
+
+	return prices
+}
+
 // GetPriceOrFirst returns the price that matches the provided PlanPricing
 // or the first price if the provided PlanPricing is nil.
 func (p Plan) GetPriceOrFirst(needle *PlanPricing) PlanPricing {
@@ -114,7 +161,12 @@ func (p Plan) GetPriceOrFirst(needle *PlanPricing) PlanPricing {
 // - Capacities: "renew"
 // If no price matches the criteria, it returns the first price.
 func (p Plan) GetFirstPrice() PlanPricing {
-	if len(p.Pricings) == 0 {
+	return firstPrice(p.Pricings)
+}
+
+// firstPrice returns the first price of the pricings, see Plan.GetFirstPrice.
+func firstPrice(pricings []PlanPricing) PlanPricing {
+	if len(pricings) == 0 {
 		return PlanPricing{}
 	}
 
@@ -129,12 +181,13 @@ func (p Plan) GetFirstPrice() PlanPricing {
 		Capacities:   []string{"renew"},
 	}
 
-	price := p.FindPrice(priceMatcher)
-	if price != nil {
-		return *price
+	for _, price := range pricings {
+		if price.Equals(priceMatcher) {
+			return price
+		}
 	}
 
-	return p.Pricings[0]
+	return pricings[0]
 }
 
 // FindPrice returns the price that matches the provided PlanPricing.
@@ -182,3 +235,45 @@ func (price PlanPricing) Equals(other PlanPricing) bool {
 func (price PlanPricing) GetPrice() float64 {
 	return float64(price.Price) / priceDivider
 }
+
+// GetMonthlyPrice returns the human readable price per month,
+// prices paid for several months at once (e.g. upfront) are divided by their interval.
+// Prices not charged by month or year, like setup fees, are returned as is.
+func (price PlanPricing) GetMonthlyPrice() float64 {
+	months := price.intervalMonths()
+	if months <= 1 {
+		return price.GetPrice()
+	}
+
+	return price.GetPrice() / float64(months)
+}
+
+// GetTotal returns the human readable total cost of the recurring price over the number of months, setup fee included.
+// The price is charged for at least its commitment, by whole intervals.
+func (price PlanPricing) GetTotal(setupFee PlanPricing, months int) float64 {
+	interval := max(price.intervalMonths(), 1)
+	term := max(months, price.Commitement)
+	periods := (term + interval - 1) / interval
+
+	return setupFee.GetPrice() + float64(periods)*price.GetPrice()
+}
+
+// GetEffectiveMonthlyPrice returns the human readable cost per month of the recurring price over its minimum term,
+// the longest of its commitment and interval, setup fee included.
+func (price PlanPricing) GetEffectiveMonthlyPrice(setupFee PlanPricing) float64 {
+	term := max(price.Commitement, price.intervalMonths(), 1)
+
+	return price.GetTotal(setupFee, term) / float64(term)
+}
+
+// intervalMonths returns the interval of the price in months, or 0 when not charged by month or year.
+func (price PlanPricing) intervalMonths() int {
+	switch price.IntervalUnit {
+	case "month":
+		return price.Interval
+	case "year":
+		return price.Interval * 12
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testPlan() Plan {
+	return Plan{
+		PlanCode: "24sk40",
+		Pricings: []PlanPricing{
+			{Capacities: []string{"installation"}, Interval: 0, IntervalUnit: "none", Mode: PriceModeDefault, Phase: 1, Price: 999000000, Type: "rental"},
+			{Capacities: []string{"renew"}, Commitement: 24, Interval: 1, IntervalUnit: "month", Mode: "degressivity24", Phase: 1, Price: 1699000000, Type: "rental"},
+			{Capacities: []string{"renew"}, Commitement: 0, Interval: 1, IntervalUnit: "month", Mode: PriceModeDefault, Phase: 1, Price: 1899000000, Strategy: "tiered", Type: "rental"},
+			{Capacities: []string{"renew"}, Commitement: 12, Interval: 12, IntervalUnit: "month", Mode: "upfront12", Phase: 1, Price: 21588000000, Type: "rental"},
+		},
+	}
+}
+
+func TestGetSetupFee(t *testing.T) {
+	plan := testPlan()
+
+	if got := plan.GetSetupFee().GetPrice(); got != 9.99 {
+		t.Errorf("expected setup fee 9.99, got %v", got)
+	}
+
+	plan.Pricings = plan.Pricings[1:]
+	if got := plan.GetSetupFee(); got.Price != 0 || got.Capacities != nil {
+		t.Errorf("expected no setup fee, got %v", got)
+	}
+}
+
+func TestGetCommitmentPrices(t *testing.T) {
+	plan := testPlan()
+
+	var modes []string
+	var monthly []float64
+	for _, price := range plan.GetCommitmentPrices() {
+		modes = append(modes, price.Mode)
+		monthly = append(monthly, price.GetMonthlyPrice())
+	}
+
+	if diff := cmp.Diff([]string{"upfront12", "degressivity24"}, modes); diff != "" {
+		t.Errorf("modes mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]float64{17.99, 16.99}, monthly); diff != "" {
+		t.Errorf("monthly prices mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetTotal(t *testing.T) {
+	plan := testPlan()
+
+	testCases := []struct {
+		mode              string
+		expectedTotals    []float64
+		expectedEffective float64
+	}{
+		{
+			mode:              PriceModeDefault,
+			expectedTotals:    []float64{28.98, 237.87, 465.75},
+			expectedEffective: 28.98,
+		},
+		{
+			mode:              "upfront12",
+			expectedTotals:    []float64{225.87, 225.87, 441.75},
+			expectedEffective: 18.82,
+		},
+		{
+			mode:              "degressivity24",
+			expectedTotals:    []float64{417.75, 417.75, 417.75},
+			expectedEffective: 17.41,
+		},
+	}
+
+	round := func(v float64) float64 {
+		return math.Round(v*100) / 100
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			var price PlanPricing
+			for _, p := range plan.Pricings {
+				if p.Mode == tc.mode && p.Interval > 0 {
+					price = p
+				}
+			}
+
+			setupFee := plan.GetSetupFeeFor(price)
+
+			var totals []float64
+			for _, months := range []int{1, 12, 24} {
+				totals = append(totals, round(price.GetTotal(setupFee, months)))
+			}
+
+			if diff := cmp.Diff(tc.expectedTotals, totals); diff != "" {
+				t.Errorf("totals mismatch (-want +got):\n%s", diff)
+			}
+			if got := round(price.GetEffectiveMonthlyPrice(setupFee)); got != tc.expectedEffective {
+				t.Errorf("expected effective monthly price %v, got %v", tc.expectedEffective, got)
+			}
+		})
+	}
+}
+
+func TestWithTax(t *testing.T) {
+	l := Locale{TaxRate: 20}
+
+	if got := l.WithTax(10); got != 12 {
+		t.Errorf("expected 12, got %v", got)
+	}
+}
@@ -0,0 +1,42 @@
+package kimsufi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ovh/go-ovh/ovh"
+)
+
+// Client is the subset of ovh.Client methods used by the Service.
+// It allows to use a fake OVH API client, see NewServiceWithClient.
+type Client interface {
+	Endpoint() string
+	CallAPIWithContext(ctx context.Context, method, path string, reqBody, resType any, needAuth bool) error
+	GetWithContext(ctx context.Context, url string, resType any) error
+	GetUnAuthWithContext(ctx context.Context, url string, resType any) error
+	PostWithContext(ctx context.Context, url string, reqBody, resType any) error
+	PostUnAuthWithContext(ctx context.Context, url string, reqBody, resType any) error
+	DeleteUnAuthWithContext(ctx context.Context, url string, resType any) error
+}
+
+// Authenticator is optionally implemented by a Client to return an authenticated Client,
+// it is used by Service.WithAuth.
+// When not implemented, an ovh.Client is created for the Client endpoint.
+type Authenticator interface {
+	WithAuth(appKey, appSecret, consumerKey string) (Client, error)
+}
+
+// Requester is optionally implemented by a Client to send raw HTTP requests,
+// it is used by the Service cache to revalidate responses with conditional requests.
+// When not implemented, expired responses are always fetched again.
+type Requester interface {
+	NewRequest(method, path string, reqBody any, needAuth bool) (*http.Request, error)
+	Do(req *http.Request) (*http.Response, error)
+	UnmarshalResponse(response *http.Response, resType any) error
+}
+
+// Ensure ovh.Client implements Client and Requester.
+var (
+	_ Client    = (*ovh.Client)(nil)
+	_ Requester = (*ovh.Client)(nil)
+)
@@ -0,0 +1,325 @@
+// Package diskcache caches OVH API responses on disk between invocations,
+// to avoid fetching the large catalog on every command.
+package diskcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DirName is the name of the cache directory.
+	DirName = "kimsufi-notifier"
+
+	entrySuffix = ".json"
+)
+
+// TTL is the time to live of the responses for an API path.
+type TTL struct {
+	// Path is matched against the end of the request URL path (e.g. /order/catalog/public/eco).
+	Path     string
+	Duration time.Duration
+}
+
+const (
+	// CatalogPath is the API path of the catalog.
+	CatalogPath = "/order/catalog/public/eco"
+	// AvailabilitiesPath is the API path of the availabilities.
+	AvailabilitiesPath = "/dedicated/server/datacenter/availabilities"
+
+	// AvailabilitiesTTLMax is the maximum time to live of the availabilities, when cached by PollingTTLs.
+	AvailabilitiesTTLMax = 10 * time.Second
+)
+
+var (
+	// DefaultTTLs are the API paths cached by default, the catalog rarely changes.
+	// Availabilities are not cached, to always report the current stock.
+	DefaultTTLs = []TTL{
+		{Path: CatalogPath, Duration: 6 * time.Hour},
+	}
+)
+
+// PollingTTLs returns the API paths cached for the commands polling availabilities every interval.
+// Availabilities are cached for at most half the interval, so that every poll gets fresh data.
+func PollingTTLs(interval time.Duration) []TTL {
+	ttl := min(interval/2, AvailabilitiesTTLMax)
+	if ttl <= 0 {
+		return DefaultTTLs
+	}
+
+	return append(slices.Clone(DefaultTTLs), TTL{Path: AvailabilitiesPath, Duration: ttl})
+}
+
+// Cache stores API responses as files in a directory.
+type Cache struct {
+	Dir  string
+	TTLs []TTL
+
+	now func() time.Time
+}
+
+// Entry is a cached response.
+type Entry struct {
+	URL        string      `json:"url"`
+	Expires    time.Time   `json:"expires"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body"`
+}
+
+// Info holds statistics about the cache directory.
+type Info struct {
+	Dir     string
+	Entries int
+	Expired int
+	Size    int64
+}
+
+// DefaultDir returns the default cache directory,
+// $XDG_CACHE_HOME/kimsufi-notifier or ~/.cache/kimsufi-notifier.
+func DefaultDir() (string, error) {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+
+	return filepath.Join(dir, DirName), nil
+}
+
+// New returns a Cache storing the responses in dir with the DefaultTTLs.
+func New(dir string) *Cache {
+	return &Cache{
+		Dir:  dir,
+		TTLs: DefaultTTLs,
+		now:  time.Now,
+	}
+}
+
+// Transport returns an http.RoundTripper serving the cached GET responses and caching the successful ones.
+// transport is optional, if nil http.DefaultTransport is used.
+// refresh ignores the cached responses, fresh responses are still cached.
+func (c *Cache) Transport(transport http.RoundTripper, refresh bool) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Transport{
+		cache:     c,
+		transport: transport,
+		refresh:   refresh,
+	}
+}
+
+// Clear removes all the cached responses and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	paths, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, path := range paths {
+		err := os.Remove(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(paths), nil
+}
+
+// Info returns statistics about the cached responses.
+func (c *Cache) Info() (*Info, error) {
+	paths, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{
+		Dir:     c.Dir,
+		Entries: len(paths),
+	}
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		info.Size += stat.Size()
+
+		e, err := c.read(path)
+		if err != nil || c.now().After(e.Expires) {
+			info.Expired++
+		}
+	}
+
+	return info, nil
+}
+
+// ttl returns the time to live for the request, or false when it is not cached.
+// Only unauthenticated GET requests are cached.
+func (c *Cache) ttl(req *http.Request) (time.Duration, bool) {
+	if req.Method != http.MethodGet || req.Header.Get("X-Ovh-Consumer") != "" {
+		return 0, false
+	}
+
+	for _, t := range c.TTLs {
+		if strings.HasSuffix(req.URL.Path, t.Path) {
+			return t.Duration, t.Duration > 0
+		}
+	}
+
+	return 0, false
+}
+
+// get returns the cached response for the URL, or nil when not found or expired.
+func (c *Cache) get(url string) *Entry {
+	e, err := c.read(c.path(url))
+	if err != nil || e.URL != url || c.now().After(e.Expires) {
+		return nil
+	}
+
+	return e
+}
+
+// set caches the response for the URL.
+// The entry is written to a temporary file first, so concurrent readers never see a partial entry.
+func (c *Cache) set(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(c.Dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // nolint:errcheck
+
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close() // nolint:errcheck
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path(e.URL))
+}
+
+func (c *Cache) read(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var e Entry
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// path returns the file path of the entry for the URL.
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+entrySuffix)
+}
+
+// entries returns the file paths of all the entries, a missing directory has no entries.
+func (c *Cache) entries() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(c.Dir, "*"+entrySuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// Transport is an http.RoundTripper caching the API responses on disk.
+type Transport struct {
+	cache     *Cache
+	transport http.RoundTripper
+	refresh   bool
+}
+
+// RoundTrip implements http.RoundTripper.
+// Failing to write the cache does not fail the request.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ttl, ok := t.cache.ttl(req)
+	if !ok {
+		return t.transport.RoundTrip(req)
+	}
+
+	url := req.URL.String()
+	if !t.refresh {
+		if e := t.cache.get(url); e != nil {
+			return e.response(req), nil
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	e := &Entry{
+		URL:        url,
+		Expires:    t.cache.now().Add(ttl),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	}
+	t.cache.set(e) // nolint:errcheck
+
+	return resp, nil
+}
+
+// response returns the http.Response for the entry.
+func (e *Entry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,265 @@
+package diskcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		io.WriteString(w, strconv.Itoa(requests)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(t.TempDir())
+	c.TTLs = PollingTTLs(time.Minute)
+	c.now = func() time.Time { return now }
+
+	client := &http.Client{Transport: c.Transport(nil, false)}
+	availabilities := server.URL + "/1.0/dedicated/server/datacenter/availabilities?planCode=24ska01"
+	catalog := server.URL + "/1.0/order/catalog/public/eco?ovhSubsidiary=FR"
+
+	testCases := []struct {
+		name     string
+		client   *http.Client
+		method   string
+		url      string
+		advance  time.Duration
+		expected string
+	}{
+		{
+			name:     "availabilities miss",
+			url:      availabilities,
+			expected: "1",
+		},
+		{
+			name:     "availabilities hit",
+			url:      availabilities,
+			advance:  5 * time.Second,
+			expected: "1",
+		},
+		{
+			name:     "catalog miss",
+			url:      catalog,
+			expected: "2",
+		},
+		{
+			name:     "availabilities expired",
+			url:      availabilities,
+			advance:  10 * time.Second,
+			expected: "3",
+		},
+		{
+			name:     "catalog hit",
+			url:      catalog,
+			advance:  time.Hour,
+			expected: "2",
+		},
+		{
+			name:     "catalog refresh",
+			client:   &http.Client{Transport: c.Transport(nil, true)},
+			url:      catalog,
+			expected: "4",
+		},
+		{
+			name:     "catalog hit after refresh",
+			url:      catalog,
+			expected: "4",
+		},
+		{
+			name:     "path not cached",
+			url:      server.URL + "/1.0/order/cart",
+			expected: "5",
+		},
+		{
+			name:     "method not cached",
+			method:   http.MethodPost,
+			url:      catalog,
+			expected: "6",
+		},
+		{
+			name:     "error not cached",
+			url:      catalog + "&fail=true",
+			expected: "7",
+		},
+		{
+			name:     "error not cached again",
+			url:      catalog + "&fail=true",
+			expected: "8",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now = now.Add(tc.advance)
+
+			cl := client
+			if tc.client != nil {
+				cl = tc.client
+			}
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req, err := http.NewRequest(method, tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := cl.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close() // nolint:errcheck
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(body) != tc.expected {
+				t.Errorf("expected body %q, got %q", tc.expected, string(body))
+			}
+		})
+	}
+
+	info, err := c.Info()
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Entries != 2 || info.Expired != 1 {
+		t.Errorf("expected 2 entries with 1 expired, got %d entries with %d expired", info.Entries, info.Expired)
+	}
+
+	removed, err := c.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 entries removed, got %d", removed)
+	}
+
+	info, err = c.Info()
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Entries != 0 || info.Size != 0 {
+		t.Errorf("expected empty cache, got %d entries of %d bytes", info.Entries, info.Size)
+	}
+}
+
+func TestTransportAuthenticatedNotCached(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: New(t.TempDir()).Transport(nil, false)}
+
+	for range 2 {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/order/catalog/public/eco", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Ovh-Consumer", "consumer-key")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close() // nolint:errcheck
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestTransportTTLs(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, strconv.Itoa(requests)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	availabilities := server.URL + "/1.0/dedicated/server/datacenter/availabilities?planCode=24ska01"
+	catalog := server.URL + "/1.0/order/catalog/public/eco?ovhSubsidiary=FR"
+
+	testCases := []struct {
+		name string
+		ttls []TTL
+		// requests are made one second apart.
+		requests []string
+		expected []string
+	}{
+		{
+			name:     "default",
+			ttls:     DefaultTTLs,
+			requests: []string{catalog, availabilities, availabilities, catalog},
+			expected: []string{"1", "2", "3", "1"},
+		},
+		{
+			name:     "polling faster than the availabilities TTL",
+			ttls:     PollingTTLs(5 * time.Second),
+			requests: []string{catalog, availabilities, availabilities, availabilities, availabilities, availabilities, availabilities, availabilities, catalog},
+			// Availabilities are cached for 2.5 seconds, every poll gets fresh data.
+			expected: []string{"1", "2", "2", "2", "3", "3", "3", "4", "1"},
+		},
+		{
+			name:     "invalid interval",
+			ttls:     PollingTTLs(0),
+			requests: []string{availabilities, availabilities},
+			expected: []string{"1", "2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = 0
+
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			c := New(t.TempDir())
+			c.TTLs = tc.ttls
+			c.now = func() time.Time { return now }
+
+			client := &http.Client{Transport: c.Transport(nil, false)}
+
+			var bodies []string
+			for _, url := range tc.requests {
+				resp, err := client.Get(url)
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+
+				body, err := io.ReadAll(resp.Body)
+				resp.Body.Close() // nolint:errcheck
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				bodies = append(bodies, string(body))
+				now = now.Add(time.Second)
+			}
+
+			if diff := cmp.Diff(tc.expected, bodies); diff != "" {
+				t.Errorf("responses mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
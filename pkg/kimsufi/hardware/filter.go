@@ -0,0 +1,67 @@
+package hardware
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Filter holds the hardware requirements of a configuration,
+// zero values are ignored.
+type Filter struct {
+	// MinRAM is the minimum memory size in GB.
+	MinRAM int
+	// ECC requires ECC memory.
+	ECC bool
+	// MinCores is the minimum number of CPU cores.
+	MinCores int
+	// CPUBrand is the CPU brand (e.g. AMD), case insensitive.
+	CPUBrand string
+	// DiskTech requires at least one disk of the technology, one of DiskTechs.
+	DiskTech string
+	// MinStorageTB is the minimum total raw capacity of the disks in TB.
+	MinStorageTB float64
+	// MinBandwidth is the minimum bandwidth in Mbit/s.
+	MinBandwidth float64
+	// MaxPrice is the maximum monthly price.
+	MaxPrice float64
+}
+
+// Validate checks the filter values.
+func (f Filter) Validate() error {
+	if f.DiskTech != "" && !slices.Contains(DiskTechs, strings.ToLower(f.DiskTech)) {
+		return fmt.Errorf("invalid disk technology %q (allowed values: %s)", f.DiskTech, strings.Join(DiskTechs, ", "))
+	}
+
+	if f.MinRAM < 0 || f.MinCores < 0 || f.MinStorageTB < 0 || f.MinBandwidth < 0 || f.MaxPrice < 0 {
+		return fmt.Errorf("minimum and maximum values must be positive")
+	}
+
+	return nil
+}
+
+// Match returns true if the configuration meets all the filter requirements.
+func (f Filter) Match(c Configuration) bool {
+	t := c.Technical
+
+	switch {
+	case f.MinRAM > 0 && t.Memory.Size < f.MinRAM:
+		return false
+	case f.ECC && !t.Memory.ECC:
+		return false
+	case f.MinCores > 0 && c.Cores() < f.MinCores:
+		return false
+	case f.CPUBrand != "" && !strings.EqualFold(t.Server.CPU.Brand, f.CPUBrand):
+		return false
+	case f.DiskTech != "" && !slices.Contains(c.DiskTechs(), strings.ToLower(f.DiskTech)):
+		return false
+	case f.MinStorageTB > 0 && c.StorageTB() < f.MinStorageTB:
+		return false
+	case f.MinBandwidth > 0 && t.Bandwidth.Level < f.MinBandwidth:
+		return false
+	case f.MaxPrice > 0 && c.Price > f.MaxPrice:
+		return false
+	}
+
+	return true
+}
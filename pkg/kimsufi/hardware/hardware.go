@@ -0,0 +1,159 @@
+// Package hardware resolves the hardware specifications of the server configurations of a catalog,
+// to search servers by their specifications.
+package hardware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
+
+const (
+	DiskTechHDD  = "hdd"
+	DiskTechSSD  = "ssd"
+	DiskTechNVMe = "nvme"
+)
+
+var (
+	// DiskTechs are the known disk technologies.
+	DiskTechs = []string{DiskTechHDD, DiskTechSSD, DiskTechNVMe}
+)
+
+// Configuration is a server configuration of a plan, with its memory and storage addons,
+// and its hardware specifications resolved from the catalog products.
+type Configuration struct {
+	PlanCode    string
+	Category    string
+	InvoiceName string
+	// Memory, Storage and Bandwidth are the generic names of the addons (e.g. ram-32g-noecc-2133),
+	// as used in availabilities.
+	Memory    string
+	Storage   string
+	Bandwidth string
+	// Technical holds the hardware specifications, memory, storage and bandwidth are taken from their addon product
+	// or from the plan product when not found.
+	Technical kimsuficatalog.ProductBlobsTechnical
+	// Price is the monthly price of the plan and its addons.
+	Price    float64
+	Currency string
+}
+
+// Configurations returns the configurations of the plan, one for each combination of memory and storage addons.
+// The default bandwidth addon is used.
+func Configurations(catalog *kimsuficatalog.Catalog, plan *kimsuficatalog.Plan) []Configuration {
+	var base kimsuficatalog.ProductBlobsTechnical
+	product := catalog.GetProduct(plan.Product)
+	if product != nil {
+		base = product.Blobs.Technical
+	}
+
+	var bandwidth string
+	if f := plan.GetAddon(kimsuficatalog.AddonBandwidth); f != nil {
+		bandwidth = f.Default
+		if bandwidth == "" && len(f.Addons) > 0 {
+			bandwidth = f.Addons[0]
+		}
+	}
+
+	var configurations []Configuration
+	for _, memory := range addons(plan, kimsuficatalog.AddonMemory) {
+		for _, storage := range addons(plan, kimsuficatalog.AddonStorage) {
+			c := Configuration{
+				PlanCode:    plan.PlanCode,
+				Category:    plan.GetCategory(),
+				InvoiceName: plan.InvoiceName,
+				Memory:      genericName(memory),
+				Storage:     genericName(storage),
+				Bandwidth:   genericName(bandwidth),
+				Technical:   base,
+				Price:       plan.GetFirstPrice().GetPrice(),
+				Currency:    catalog.Locale.CurrencyCode,
+			}
+
+			if p := catalog.GetProduct(c.Memory); c.Memory != "" && p != nil {
+				c.Technical.Memory = p.Blobs.Technical.Memory
+			}
+			if p := catalog.GetProduct(c.Storage); c.Storage != "" && p != nil {
+				c.Technical.Storage = p.Blobs.Technical.Storage
+			}
+			if p := catalog.GetProduct(c.Bandwidth); c.Bandwidth != "" && p != nil {
+				c.Technical.Bandwidth = p.Blobs.Technical.Bandwidth
+			}
+
+			for _, addon := range []string{memory, storage, bandwidth} {
+				if a := catalog.GetAddon(addon); addon != "" && a != nil {
+					c.Price += a.GetFirstPrice().GetPrice()
+				}
+			}
+
+			configurations = append(configurations, c)
+		}
+	}
+
+	return configurations
+}
+
+// Cores returns the total number of CPU cores.
+func (c Configuration) Cores() int {
+	return c.Technical.Server.CPU.Cores * max(c.Technical.Server.CPU.Number, 1)
+}
+
+// StorageTB returns the total raw capacity of the disks in TB.
+func (c Configuration) StorageTB() float64 {
+	var capacity int
+	for _, disk := range c.Technical.Storage.Disks {
+		capacity += disk.Number * disk.Capacity
+	}
+
+	return float64(capacity) / 1000
+}
+
+// DiskTechs returns the technologies of the disks (e.g. ssd), without duplicates.
+func (c Configuration) DiskTechs() []string {
+	var techs []string
+	for _, disk := range c.Technical.Storage.Disks {
+		tech := DiskTech(disk)
+		if tech != "" && !slices.Contains(techs, tech) {
+			techs = append(techs, tech)
+		}
+	}
+
+	return techs
+}
+
+// DiskTech returns the technology of the disk, one of DiskTechs,
+// or an empty string when unknown.
+func DiskTech(disk kimsuficatalog.ProductBlobsTechnicalStorageDisk) string {
+	switch {
+	case strings.EqualFold(disk.Interface, "NVMe") || strings.EqualFold(disk.Technology, "NVMe"):
+		return DiskTechNVMe
+	case strings.EqualFold(disk.Technology, "SSD"):
+		return DiskTechSSD
+	case strings.EqualFold(disk.Technology, "HDD") || strings.EqualFold(disk.Technology, "SATA") || strings.EqualFold(disk.Technology, "SAS"):
+		return DiskTechHDD
+	default:
+		return ""
+	}
+}
+
+// addons returns the addons of the family, or a single empty addon when the plan has none,
+// so that plans without options still have a configuration.
+func addons(plan *kimsuficatalog.Plan, family string) []string {
+	f := plan.GetAddon(family)
+	if f == nil || len(f.Addons) == 0 {
+		return []string{""}
+	}
+
+	return f.Addons
+}
+
+// genericName returns the generic name of the addon, or an empty string for an empty addon.
+func genericName(addon string) string {
+	if addon == "" {
+		return ""
+	}
+
+	return kimsufi.AddonGenericName(addon)
+}
@@ -0,0 +1,302 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/expr"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
+
+// price returns a monthly price of the given value, in the catalog price unit.
+func price(value float64) []kimsuficatalog.PlanPricing {
+	return []kimsuficatalog.PlanPricing{
+		{
+			Capacities:   []string{"renew"},
+			Interval:     1,
+			IntervalUnit: "month",
+			Mode:         kimsuficatalog.PriceModeDefault,
+			Phase:        1,
+			Price:        int(value * 1e8),
+			Strategy:     "tiered",
+			Type:         "rental",
+		},
+	}
+}
+
+func testCatalog() *kimsuficatalog.Catalog {
+	return &kimsuficatalog.Catalog{
+		Locale: kimsuficatalog.Locale{CurrencyCode: "EUR"},
+		Plans: []kimsuficatalog.Plan{
+			{
+				PlanCode:    "24ska01",
+				InvoiceName: "KS-A | Intel i7-6700k",
+				Product:     "ks-a",
+				Pricings:    price(10),
+				AddonFamilies: []kimsuficatalog.PlanAddonFamily{
+					{Name: kimsuficatalog.AddonMemory, Addons: []string{"ram-32g-noecc-2133-24ska01", "ram-64g-ecc-2133-24ska01"}},
+					{Name: kimsuficatalog.AddonStorage, Addons: []string{"softraid-2x2000sa-24ska01", "softraid-2x480nvme-24ska01"}},
+					{Name: kimsuficatalog.AddonBandwidth, Addons: []string{"bandwidth-100-24ska01", "bandwidth-1000-24ska01"}, Default: "bandwidth-100-24ska01"},
+				},
+			},
+			{
+				PlanCode:    "25skle01",
+				InvoiceName: "KS-LE-1",
+				Product:     "ks-le-1",
+				Pricings:    price(20),
+			},
+		},
+		Addons: []kimsuficatalog.Addon{
+			{PlanCode: "ram-32g-noecc-2133-24ska01", Pricings: price(0)},
+			{PlanCode: "ram-64g-ecc-2133-24ska01", Pricings: price(5)},
+			{PlanCode: "softraid-2x480nvme-24ska01", Pricings: price(2.5)},
+		},
+		Products: []kimsuficatalog.Product{
+			{
+				Name: "ks-a",
+				Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{
+					Server: kimsuficatalog.ProductBlobsTechnicalServer{CPU: kimsuficatalog.ProductBlobsTechnicalCPU{Brand: "Intel", Cores: 4, Threads: 8}},
+				}},
+			},
+			{
+				Name: "ks-le-1",
+				Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{
+					Server:    kimsuficatalog.ProductBlobsTechnicalServer{CPU: kimsuficatalog.ProductBlobsTechnicalCPU{Brand: "AMD", Cores: 8, Number: 1}},
+					Memory:    kimsuficatalog.ProductBlobsTechnicalMemory{Size: 16},
+					Storage:   kimsuficatalog.ProductBlobsTechnicalStorage{Disks: []kimsuficatalog.ProductBlobsTechnicalStorageDisk{{Number: 2, Capacity: 480, Technology: "SSD"}}},
+					Bandwidth: kimsuficatalog.ProductBlobsTechnicalBandwidth{Level: 1000},
+				}},
+			},
+			{Name: "ram-32g-noecc-2133", Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{Memory: kimsuficatalog.ProductBlobsTechnicalMemory{Size: 32}}}},
+			{Name: "ram-64g-ecc-2133", Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{Memory: kimsuficatalog.ProductBlobsTechnicalMemory{Size: 64, ECC: true}}}},
+			{Name: "softraid-2x2000sa", Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{Storage: kimsuficatalog.ProductBlobsTechnicalStorage{Disks: []kimsuficatalog.ProductBlobsTechnicalStorageDisk{{Number: 2, Capacity: 2000, Technology: "HDD", Interface: "SATA"}}}}}},
+			{Name: "softraid-2x480nvme", Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{Storage: kimsuficatalog.ProductBlobsTechnicalStorage{Disks: []kimsuficatalog.ProductBlobsTechnicalStorageDisk{{Number: 2, Capacity: 480, Technology: "SSD", Interface: "NVMe"}}}}}},
+			{Name: "bandwidth-100", Blobs: kimsuficatalog.ProductBlobs{Technical: kimsuficatalog.ProductBlobsTechnical{Bandwidth: kimsuficatalog.ProductBlobsTechnicalBandwidth{Level: 100}}}},
+		},
+	}
+}
+
+// summary is the part of a configuration compared in tests.
+type summary struct {
+	PlanCode  string
+	Memory    string
+	Storage   string
+	Bandwidth string
+	RAM       int
+	StorageTB float64
+	DiskTechs []string
+	Price     float64
+}
+
+func summarize(configurations []Configuration) []summary {
+	var s []summary
+	for _, c := range configurations {
+		s = append(s, summary{
+			PlanCode:  c.PlanCode,
+			Memory:    c.Memory,
+			Storage:   c.Storage,
+			Bandwidth: c.Bandwidth,
+			RAM:       c.Technical.Memory.Size,
+			StorageTB: c.StorageTB(),
+			DiskTechs: c.DiskTechs(),
+			Price:     c.Price,
+		})
+	}
+
+	return s
+}
+
+func TestConfigurations(t *testing.T) {
+	catalog := testCatalog()
+
+	testCases := []struct {
+		planCode string
+		expected []summary
+	}{
+		{
+			planCode: "24ska01",
+			expected: []summary{
+				{PlanCode: "24ska01", Memory: "ram-32g-noecc-2133", Storage: "softraid-2x2000sa", Bandwidth: "bandwidth-100", RAM: 32, StorageTB: 4, DiskTechs: []string{DiskTechHDD}, Price: 10},
+				{PlanCode: "24ska01", Memory: "ram-32g-noecc-2133", Storage: "softraid-2x480nvme", Bandwidth: "bandwidth-100", RAM: 32, StorageTB: 0.96, DiskTechs: []string{DiskTechNVMe}, Price: 12.5},
+				{PlanCode: "24ska01", Memory: "ram-64g-ecc-2133", Storage: "softraid-2x2000sa", Bandwidth: "bandwidth-100", RAM: 64, StorageTB: 4, DiskTechs: []string{DiskTechHDD}, Price: 15},
+				{PlanCode: "24ska01", Memory: "ram-64g-ecc-2133", Storage: "softraid-2x480nvme", Bandwidth: "bandwidth-100", RAM: 64, StorageTB: 0.96, DiskTechs: []string{DiskTechNVMe}, Price: 17.5},
+			},
+		},
+		{
+			planCode: "25skle01",
+			expected: []summary{
+				{PlanCode: "25skle01", RAM: 16, StorageTB: 0.96, DiskTechs: []string{DiskTechSSD}, Price: 20},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.planCode, func(t *testing.T) {
+			configurations := Configurations(catalog, catalog.GetPlan(tc.planCode))
+			if diff := cmp.Diff(tc.expected, summarize(configurations)); diff != "" {
+				t.Errorf("Configurations() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	catalog := testCatalog()
+
+	var configurations []Configuration
+	for _, plan := range catalog.Plans {
+		configurations = append(configurations, Configurations(catalog, &plan)...)
+	}
+
+	testCases := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			filter:   Filter{},
+			expected: []string{"24ska01 ram-32g-noecc-2133 softraid-2x2000sa", "24ska01 ram-32g-noecc-2133 softraid-2x480nvme", "24ska01 ram-64g-ecc-2133 softraid-2x2000sa", "24ska01 ram-64g-ecc-2133 softraid-2x480nvme", "25skle01  "},
+		},
+		{
+			name:     "min ram and ecc",
+			filter:   Filter{MinRAM: 32, ECC: true},
+			expected: []string{"24ska01 ram-64g-ecc-2133 softraid-2x2000sa", "24ska01 ram-64g-ecc-2133 softraid-2x480nvme"},
+		},
+		{
+			name:     "min cores and cpu brand",
+			filter:   Filter{MinCores: 8, CPUBrand: "amd"},
+			expected: []string{"25skle01  "},
+		},
+		{
+			name:     "disk technology",
+			filter:   Filter{DiskTech: "NVMe"},
+			expected: []string{"24ska01 ram-32g-noecc-2133 softraid-2x480nvme", "24ska01 ram-64g-ecc-2133 softraid-2x480nvme"},
+		},
+		{
+			name:     "min storage and max price",
+			filter:   Filter{MinStorageTB: 4, MaxPrice: 12},
+			expected: []string{"24ska01 ram-32g-noecc-2133 softraid-2x2000sa"},
+		},
+		{
+			name:     "min bandwidth",
+			filter:   Filter{MinBandwidth: 1000},
+			expected: []string{"25skle01  "},
+		},
+		{
+			name:   "no match",
+			filter: Filter{MinRAM: 128},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var matches []string
+			for _, c := range configurations {
+				if tc.filter.Match(c) {
+					matches = append(matches, c.PlanCode+" "+c.Memory+" "+c.Storage)
+				}
+			}
+
+			if diff := cmp.Diff(tc.expected, matches); diff != "" {
+				t.Errorf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        Filter
+		expectedError bool
+	}{
+		{
+			name:   "valid",
+			filter: Filter{MinRAM: 32, DiskTech: "SSD"},
+		},
+		{
+			name:          "invalid disk technology",
+			filter:        Filter{DiskTech: "tape"},
+			expectedError: true,
+		},
+		{
+			name:          "negative value",
+			filter:        Filter{MaxPrice: -1},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.filter.Validate()
+			if tc.expectedError != (err != nil) {
+				t.Errorf("expected error %t, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestLookupRow(t *testing.T) {
+	catalog := testCatalog()
+
+	testCases := []struct {
+		name        string
+		planCode    string
+		memory      string
+		storage     string
+		datacenters kimsufiavailability.Datacenters
+		expression  string
+		expected    bool
+	}{
+		{
+			name:        "matching configuration",
+			planCode:    "24ska01",
+			memory:      "ram-64g-ecc-2133",
+			storage:     "softraid-2x480nvme",
+			datacenters: kimsufiavailability.Datacenters{{Datacenter: "gra", Availability: "1H-low"}},
+			expression:  `ram >= 32 && ecc && price < 20 && "nvme" in disk_tech && dc in ["gra","rbx"] && available`,
+			expected:    true,
+		},
+		{
+			name:        "other memory",
+			planCode:    "24ska01",
+			memory:      "ram-32g-noecc-2133",
+			storage:     "softraid-2x480nvme",
+			datacenters: kimsufiavailability.Datacenters{{Datacenter: "gra", Availability: "1H-low"}},
+			expression:  `ram >= 32 && ecc`,
+			expected:    false,
+		},
+		{
+			name:       "plan without options",
+			planCode:   "25skle01",
+			memory:     "ram-16g-noecc-1333",
+			storage:    "softraid-2x480ssd",
+			expression: `brand == "amd" && cores >= 8 && storage_tb > 0.9 && memory == "ram-16g-noecc-1333" && !available`,
+			expected:   true,
+		},
+		{
+			name:       "plan not in catalog",
+			planCode:   "unknown",
+			memory:     "ram-16g-noecc-1333",
+			expression: `plan == "unknown" && memory == "ram-16g-noecc-1333" && ram == 0`,
+			expected:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := expr.Compile(tc.expression, RowVars)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			c := Lookup(catalog, tc.planCode, tc.memory, tc.storage)
+			if got := e.Match(c.Row(tc.datacenters)); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
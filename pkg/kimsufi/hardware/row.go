@@ -0,0 +1,101 @@
+package hardware
+
+import (
+	"strings"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/expr"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
+
+var (
+	// RowVars are the variables of a Row, usable in filter expressions.
+	RowVars = expr.Vars{
+		"plan":       expr.TypeString,
+		"category":   expr.TypeString,
+		"name":       expr.TypeString,
+		"memory":     expr.TypeString,
+		"storage":    expr.TypeString,
+		"cpu":        expr.TypeString,
+		"brand":      expr.TypeString,
+		"cores":      expr.TypeNumber,
+		"threads":    expr.TypeNumber,
+		"frequency":  expr.TypeNumber,
+		"ram":        expr.TypeNumber,
+		"ecc":        expr.TypeBool,
+		"storage_tb": expr.TypeNumber,
+		"disk_tech":  expr.TypeStringList,
+		"bandwidth":  expr.TypeNumber,
+		"price":      expr.TypeNumber,
+		"currency":   expr.TypeString,
+		"available":  expr.TypeBool,
+		"dc":         expr.TypeStringList,
+	}
+)
+
+// Row returns the flattened view of the configuration available in the datacenters,
+// with the variables declared in RowVars.
+func (c Configuration) Row(datacenters kimsufiavailability.Datacenters) expr.Row {
+	cpu := c.Technical.Server.CPU
+
+	return expr.Row{
+		"plan":       c.PlanCode,
+		"category":   c.Category,
+		"name":       c.InvoiceName,
+		"memory":     c.Memory,
+		"storage":    c.Storage,
+		"cpu":        strings.TrimSpace(cpu.Brand + " " + cpu.Model),
+		"brand":      cpu.Brand,
+		"cores":      c.Cores(),
+		"threads":    cpu.Threads * max(cpu.Number, 1),
+		"frequency":  cpu.Frequency,
+		"ram":        c.Technical.Memory.Size,
+		"ecc":        c.Technical.Memory.ECC,
+		"storage_tb": c.StorageTB(),
+		"disk_tech":  c.DiskTechs(),
+		"bandwidth":  c.Technical.Bandwidth.Level,
+		"price":      c.Price,
+		"currency":   c.Currency,
+		"available":  datacenters.Status() == kimsufiavailability.StatusAvailable,
+		"dc":         datacenters.Codes(),
+	}
+}
+
+// Lookup returns the configuration of the plan with the memory and storage addons, given by their generic names.
+// When the plan or the configuration is not in the catalog, only the plan code, memory and storage are set.
+func Lookup(catalog *kimsuficatalog.Catalog, planCode, memory, storage string) Configuration {
+	if catalog != nil {
+		if plan := catalog.GetPlan(planCode); plan != nil {
+			for _, c := range Configurations(catalog, plan) {
+				if (c.Memory == "" || c.Memory == memory) && (c.Storage == "" || c.Storage == storage) {
+					c.Memory, c.Storage = memory, storage
+					return c
+				}
+			}
+		}
+	}
+
+	return Configuration{
+		PlanCode: planCode,
+		Memory:   memory,
+		Storage:  storage,
+	}
+}
+
+// Availabilities returns the availabilities of the configuration,
+// the memory and storage are ignored when the plan has no such options.
+func (c Configuration) Availabilities(availabilities kimsufiavailability.Availabilities) kimsufiavailability.Availabilities {
+	var result kimsufiavailability.Availabilities
+	for _, a := range availabilities.GetByPlanCode(c.PlanCode) {
+		if c.Memory != "" && a.Memory != c.Memory {
+			continue
+		}
+		if c.Storage != "" && a.Storage != c.Storage {
+			continue
+		}
+
+		result = append(result, a)
+	}
+
+	return result
+}
@@ -0,0 +1,57 @@
+package kimsufi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// EndpointResult holds the result of a function run against the Service of an endpoint.
+type EndpointResult[T any] struct {
+	Endpoint string
+	Value    T
+	Err      error
+}
+
+// RunAll runs fn concurrently against the Service of every endpoint of m,
+// with at most workers endpoints at once, 0 or less runs all endpoints at once.
+// The results are returned in the endpoints order, errors are wrapped with the endpoint name.
+func RunAll[T any](ctx context.Context, m MultiService, workers int, fn func(ctx context.Context, endpoint string, s *Service) (T, error)) []EndpointResult[T] {
+	endpoints := slices.Sorted(maps.Keys(m))
+
+	if workers <= 0 || workers > len(endpoints) {
+		workers = len(endpoints)
+	}
+
+	results := make([]EndpointResult[T], len(endpoints))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range jobs {
+				endpoint := endpoints[i]
+				value, err := fn(ctx, endpoint, m.Endpoint(endpoint))
+				if err != nil {
+					err = fmt.Errorf("%s: %w", endpoint, err)
+				}
+
+				results[i] = EndpointResult[T]{
+					Endpoint: endpoint,
+					Value:    value,
+					Err:      err,
+				}
+			}
+		})
+	}
+
+	for i := range endpoints {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
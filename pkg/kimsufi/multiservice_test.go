@@ -0,0 +1,68 @@
+package kimsufi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunAll(t *testing.T) {
+	m := MultiService{
+		"ovh-us": *NewServiceWithClient(&fakeClient{}, nil, nil),
+		"ovh-eu": *NewServiceWithClient(&fakeClient{}, nil, nil),
+		"ovh-ca": *NewServiceWithClient(&fakeClient{}, nil, nil),
+	}
+
+	var running, maxRunning atomic.Int32
+	results := RunAll(context.Background(), m, 2, func(ctx context.Context, endpoint string, s *Service) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if s == nil {
+			return "", errors.New("no service")
+		}
+		if endpoint == "ovh-ca" {
+			return "", errors.New("unavailable")
+		}
+
+		return "catalog of " + endpoint, nil
+	})
+
+	var (
+		endpoints []string
+		values    []string
+		errs      []string
+	)
+	for _, r := range results {
+		endpoints = append(endpoints, r.Endpoint)
+		values = append(values, r.Value)
+		if r.Err != nil {
+			errs = append(errs, r.Err.Error())
+		}
+	}
+
+	if diff := cmp.Diff([]string{"ovh-ca", "ovh-eu", "ovh-us"}, endpoints); diff != "" {
+		t.Errorf("endpoints mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"", "catalog of ovh-eu", "catalog of ovh-us"}, values); diff != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"ovh-ca: unavailable"}, errs); diff != "" {
+		t.Errorf("errors mismatch (-want +got):\n%s", diff)
+	}
+
+	if maxRunning.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent workers, got %d", maxRunning.Load())
+	}
+}
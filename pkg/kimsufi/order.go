@@ -1,6 +1,7 @@
 package kimsufi
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"time"
@@ -10,6 +11,11 @@ import (
 
 // CreateCart creates a new cart which will expire at the given time.
 func (s *Service) CreateCart(ovhSubsidiary string, expire time.Time) (*kimsufiorder.CartResponse, error) {
+	return s.CreateCartWithContext(context.Background(), ovhSubsidiary, expire)
+}
+
+// CreateCartWithContext is like CreateCart but uses ctx for the API requests.
+func (s *Service) CreateCartWithContext(ctx context.Context, ovhSubsidiary string, expire time.Time) (*kimsufiorder.CartResponse, error) {
 	u := "/order/cart"
 
 	req := kimsufiorder.CartRequest{
@@ -20,7 +26,7 @@ func (s *Service) CreateCart(ovhSubsidiary string, expire time.Time) (*kimsufior
 	s.logger.Debugf("CreateCart request: %+#v", req)
 
 	var resp kimsufiorder.CartResponse
-	err := s.client.PostUnAuth(u, req, &resp)
+	err := s.client.PostUnAuthWithContext(ctx, u, req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +36,11 @@ func (s *Service) CreateCart(ovhSubsidiary string, expire time.Time) (*kimsufior
 
 // AddEcoItem adds an OVH eco item to the cart with the given planCode, quantity and duration, mode from priceConfig.
 func (s *Service) AddEcoItem(cartID, planCode string, quantity int, priceConfig kimsufiorder.EcoItemPriceConfig) (*kimsufiorder.EcoItemResponse, error) {
+	return s.AddEcoItemWithContext(context.Background(), cartID, planCode, quantity, priceConfig)
+}
+
+// AddEcoItemWithContext is like AddEcoItem but uses ctx for the API requests.
+func (s *Service) AddEcoItemWithContext(ctx context.Context, cartID, planCode string, quantity int, priceConfig kimsufiorder.EcoItemPriceConfig) (*kimsufiorder.EcoItemResponse, error) {
 	u := fmt.Sprintf("/order/cart/%s/eco", cartID)
 
 	req := kimsufiorder.EcoItemRequest{
@@ -41,7 +52,7 @@ func (s *Service) AddEcoItem(cartID, planCode string, quantity int, priceConfig
 	s.logger.Debugf("AddEcoItem request: %+#v", req)
 
 	var resp kimsufiorder.EcoItemResponse
-	err := s.client.PostUnAuth(u, req, &resp)
+	err := s.client.PostUnAuthWithContext(ctx, u, req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +62,11 @@ func (s *Service) AddEcoItem(cartID, planCode string, quantity int, priceConfig
 
 // GetEcoInfo returns information about an eco item in the cart.
 func (s *Service) GetEcoInfo(cartID, planCode string) (kimsufiorder.EcoItemInfos, error) {
+	return s.GetEcoInfoWithContext(context.Background(), cartID, planCode)
+}
+
+// GetEcoInfoWithContext is like GetEcoInfo but uses ctx for the API requests.
+func (s *Service) GetEcoInfoWithContext(ctx context.Context, cartID, planCode string) (kimsufiorder.EcoItemInfos, error) {
 	u, err := url.Parse(fmt.Sprintf("/order/cart/%s/eco", cartID))
 	if err != nil {
 		return nil, err
@@ -61,7 +77,7 @@ func (s *Service) GetEcoInfo(cartID, planCode string) (kimsufiorder.EcoItemInfos
 	u.RawQuery = q.Encode()
 
 	var resp kimsufiorder.EcoItemInfos
-	err = s.client.GetUnAuth(u.String(), &resp)
+	err = s.client.GetUnAuthWithContext(ctx, u.String(), &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +87,11 @@ func (s *Service) GetEcoInfo(cartID, planCode string) (kimsufiorder.EcoItemInfos
 
 // GetEcoOptions returns the options for an eco item in the cart.
 func (s *Service) GetEcoOptions(cartID string, planCode string) (kimsufiorder.EcoItemOptions, error) {
+	return s.GetEcoOptionsWithContext(context.Background(), cartID, planCode)
+}
+
+// GetEcoOptionsWithContext is like GetEcoOptions but uses ctx for the API requests.
+func (s *Service) GetEcoOptionsWithContext(ctx context.Context, cartID string, planCode string) (kimsufiorder.EcoItemOptions, error) {
 	u, err := url.Parse(fmt.Sprintf("/order/cart/%s/eco/options", cartID))
 	if err != nil {
 		return nil, err
@@ -81,7 +102,7 @@ func (s *Service) GetEcoOptions(cartID string, planCode string) (kimsufiorder.Ec
 	u.RawQuery = q.Encode()
 
 	var options kimsufiorder.EcoItemOptions
-	err = s.client.GetUnAuth(u.String(), &options)
+	err = s.client.GetUnAuthWithContext(ctx, u.String(), &options)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +113,11 @@ func (s *Service) GetEcoOptions(cartID string, planCode string) (kimsufiorder.Ec
 // ConfigureEcoItemOption configures the item options in the cart.
 // It finds the cheapest mandatory options and merges them into the user options.
 func (s *Service) ConfigureEcoItemOption(cartID string, itemID int, option kimsufiorder.Option, priceConfig kimsufiorder.EcoItemPriceConfig) error {
+	return s.ConfigureEcoItemOptionWithContext(context.Background(), cartID, itemID, option, priceConfig)
+}
+
+// ConfigureEcoItemOptionWithContext is like ConfigureEcoItemOption but uses ctx for the API requests.
+func (s *Service) ConfigureEcoItemOptionWithContext(ctx context.Context, cartID string, itemID int, option kimsufiorder.Option, priceConfig kimsufiorder.EcoItemPriceConfig) error {
 	u := fmt.Sprintf("/order/cart/%s/eco/options", cartID)
 
 	req := kimsufiorder.EcoItemOptionRequest{
@@ -104,15 +130,20 @@ func (s *Service) ConfigureEcoItemOption(cartID string, itemID int, option kimsu
 	}
 
 	s.logger.Debugf("ConfigureItemOptions request: %+#v", req)
-	return s.client.PostUnAuth(u, req, nil)
+	return s.client.PostUnAuthWithContext(ctx, u, req, nil)
 }
 
 // GetItemRequiredConfiguration returns the required configuration options for an item in the cart.
 func (s *Service) GetItemRequiredConfiguration(cartID string, itemID int) ([]kimsufiorder.ItemConfiguration, error) {
+	return s.GetItemRequiredConfigurationWithContext(context.Background(), cartID, itemID)
+}
+
+// GetItemRequiredConfigurationWithContext is like GetItemRequiredConfiguration but uses ctx for the API requests.
+func (s *Service) GetItemRequiredConfigurationWithContext(ctx context.Context, cartID string, itemID int) ([]kimsufiorder.ItemConfiguration, error) {
 	u := fmt.Sprintf("/order/cart/%s/item/%d/requiredConfiguration", cartID, itemID)
 
 	var resp []kimsufiorder.ItemConfiguration
-	err := s.client.GetUnAuth(u, &resp)
+	err := s.client.GetUnAuthWithContext(ctx, u, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -122,11 +153,16 @@ func (s *Service) GetItemRequiredConfiguration(cartID string, itemID int) ([]kim
 
 // ConfigureItem configures an item in the cart with the given configurations.
 func (s *Service) AddItemConfiguration(cartID string, itemID int, configuration kimsufiorder.ItemConfigurationRequest) (*kimsufiorder.ItemConfigurationResponse, error) {
+	return s.AddItemConfigurationWithContext(context.Background(), cartID, itemID, configuration)
+}
+
+// AddItemConfigurationWithContext is like AddItemConfiguration but uses ctx for the API requests.
+func (s *Service) AddItemConfigurationWithContext(ctx context.Context, cartID string, itemID int, configuration kimsufiorder.ItemConfigurationRequest) (*kimsufiorder.ItemConfigurationResponse, error) {
 	u := fmt.Sprintf("/order/cart/%s/item/%d/configuration", cartID, itemID)
 
 	var resp kimsufiorder.ItemConfigurationResponse
 	s.logger.Debugf("ConfigureItem request: %+#v", configuration)
-	err := s.client.PostUnAuth(u, configuration, &resp)
+	err := s.client.PostUnAuthWithContext(ctx, u, configuration, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -136,16 +172,26 @@ func (s *Service) AddItemConfiguration(cartID string, itemID int, configuration
 
 // RemoveItemConfiguration removes a configuration from an item in the cart.
 func (s *Service) RemoveItemConfiguration(cartID string, itemID, configurationID int) error {
+	return s.RemoveItemConfigurationWithContext(context.Background(), cartID, itemID, configurationID)
+}
+
+// RemoveItemConfigurationWithContext is like RemoveItemConfiguration but uses ctx for the API requests.
+func (s *Service) RemoveItemConfigurationWithContext(ctx context.Context, cartID string, itemID, configurationID int) error {
 	u := fmt.Sprintf("/order/cart/%s/item/%d/configuration/%d", cartID, itemID, configurationID)
 
-	return s.client.DeleteUnAuth(u, nil)
+	return s.client.DeleteUnAuthWithContext(ctx, u, nil)
 }
 
 // AssignCart assigns the cart to the user's account.
 func (s *Service) AssignCart(cartID string) error {
+	return s.AssignCartWithContext(context.Background(), cartID)
+}
+
+// AssignCartWithContext is like AssignCart but uses ctx for the API requests.
+func (s *Service) AssignCartWithContext(ctx context.Context, cartID string) error {
 	u := fmt.Sprintf("/order/cart/%s/assign", cartID)
 
-	err := s.client.Post(u, nil, nil)
+	err := s.client.PostWithContext(ctx, u, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -156,6 +202,11 @@ func (s *Service) AssignCart(cartID string) error {
 // CheckoutCart checks out the cart to place the order.
 // If autoPay is true, the order will be paid automatically using the preferred payment method.
 func (s *Service) CheckoutCart(cartID string, autoPay bool) (*kimsufiorder.CheckoutResponse, error) {
+	return s.CheckoutCartWithContext(context.Background(), cartID, autoPay)
+}
+
+// CheckoutCartWithContext is like CheckoutCart but uses ctx for the API requests.
+func (s *Service) CheckoutCartWithContext(ctx context.Context, cartID string, autoPay bool) (*kimsufiorder.CheckoutResponse, error) {
 	u := fmt.Sprintf("/order/cart/%s/checkout", cartID)
 
 	req := kimsufiorder.CheckoutRequest{
@@ -165,7 +216,7 @@ func (s *Service) CheckoutCart(cartID string, autoPay bool) (*kimsufiorder.Check
 	s.logger.Debugf("CheckoutCart request: %+#v", req)
 
 	var resp kimsufiorder.CheckoutResponse
-	err := s.client.Post(u, req, &resp)
+	err := s.client.PostWithContext(ctx, u, req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,174 @@
+package orderflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+)
+
+// Executor runs the order cart flow against the OVH API.
+type Executor struct {
+	Service *kimsufi.Service
+	Planner Planner
+	// OnEvent is called on every step of the order flow, it is optional.
+	OnEvent func(Event)
+}
+
+// NewExecutor returns an Executor using the default Planner, onEvent is optional.
+func NewExecutor(k *kimsufi.Service, onEvent func(Event)) *Executor {
+	return &Executor{
+		Service: k,
+		Planner: NewPlanner(),
+		OnEvent: onEvent,
+	}
+}
+
+// Execute runs the order cart flow: it creates a cart, adds and configures the plan item,
+// then tries to checkout each options combination in each datacenter until one succeeds.
+// It returns the checkout response of the completed order,
+// or nil when running in dry-run mode or when no combination is available.
+// Canceling ctx stops the flow before the next API request and returns the context error.
+func (e *Executor) Execute(ctx context.Context, req OrderRequest) (*kimsufiorder.CheckoutResponse, error) {
+	k := e.Service
+
+	// Create cart
+	expire := time.Now().AddDate(0, 0, 1)
+	cart, err := k.CreateCartWithContext(ctx, req.Subsidiary, expire)
+	if err != nil {
+		return nil, err
+	}
+	e.emit(Event{Type: EventCartCreated, CartID: cart.CartID})
+
+	// Retrieve item options
+	ecoOptions, err := k.GetEcoOptionsWithContext(ctx, cart.CartID, req.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve item informations
+	ecoInfo, err := k.GetEcoInfoWithContext(ctx, cart.CartID, req.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure price config is valid, otherwise use default
+	priceConfig := ecoInfo.GetPriceConfigOrDefault(req.PlanCode, req.PriceConfig)
+
+	// Add plan to cart
+	item, err := k.AddEcoItemWithContext(ctx, cart.CartID, req.PlanCode, req.Quantity, priceConfig)
+	if err != nil {
+		return nil, err
+	}
+	e.emit(Event{Type: EventItemAdded, CartID: cart.CartID, ItemID: item.ItemID})
+
+	requiredConfigurations, err := k.GetItemRequiredConfigurationWithContext(ctx, cart.CartID, item.ItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare item configurations
+	autoConfigs := k.GenerateItemAutoConfigurations(requiredConfigurations)
+	configurations, err := e.Planner.Configurations(req, autoConfigs, requiredConfigurations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure item
+	for _, configuration := range configurations {
+		resp, err := k.AddItemConfigurationWithContext(ctx, cart.CartID, item.ItemID, configuration)
+		if err != nil {
+			return nil, err
+		}
+		e.emit(Event{Type: EventItemConfigured, CartID: cart.CartID, ItemID: item.ItemID, Label: resp.Label, Value: resp.Value})
+	}
+
+	// Prepare attempt plan
+	plan, err := e.Planner.Plan(req, ecoOptions)
+	if err != nil {
+		return nil, err
+	}
+	e.emit(Event{Type: EventPlanned, CartID: cart.CartID, ItemID: item.ItemID, Plan: plan})
+
+	// Stop on dry-run
+	if req.DryRun {
+		e.emit(Event{Type: EventDryRun, CartID: cart.CartID, ItemID: item.ItemID})
+		return nil, nil
+	}
+
+	// Authenticate
+	k, err = k.WithAuth(req.Credentials.AppKey, req.Credentials.AppSecret, req.Credentials.ConsumerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assign cart to user account
+	err = k.AssignCartWithContext(ctx, cart.CartID)
+	if err != nil {
+		return nil, err
+	}
+	e.emit(Event{Type: EventCartAssigned, CartID: cart.CartID})
+
+	// Try all options combinations
+	for _, options := range plan.Combinations {
+		// Configure item options
+		for _, option := range options {
+			err = k.ConfigureEcoItemOptionWithContext(ctx, cart.CartID, item.ItemID, option, priceConfig)
+			if err != nil {
+				return nil, err
+			}
+			e.emit(Event{Type: EventOptionSet, CartID: cart.CartID, ItemID: item.ItemID, Label: option.Family, Value: option.PlanCode})
+		}
+
+		// Try all datacenters
+		for _, datacenter := range plan.Datacenters {
+			err = ctx.Err()
+			if err != nil {
+				return nil, err
+			}
+
+			datacenterConfiguration := kimsufiorder.ItemConfigurationRequest{
+				Label: kimsufiorder.ConfigurationLabelDatacenter,
+				Value: datacenter,
+			}
+
+			resp, err := k.AddItemConfigurationWithContext(ctx, cart.CartID, item.ItemID, datacenterConfiguration)
+			if err != nil {
+				return nil, err
+			}
+			e.emit(Event{Type: EventDatacenterConfigured, CartID: cart.CartID, ItemID: item.ItemID, Datacenter: resp.Value})
+
+			// Checkout and complete the order
+			checkoutResp, err := k.CheckoutCartWithContext(ctx, cart.CartID, req.AutoPay)
+			if err == nil {
+				e.emit(Event{Type: EventOrderCompleted, CartID: cart.CartID, ItemID: item.ItemID, Datacenter: datacenter, Checkout: checkoutResp})
+				return checkoutResp, nil
+			}
+
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			if kimsufi.IsNotAvailableError(err) {
+				e.emit(Event{Type: EventDatacenterNotAvailable, CartID: cart.CartID, ItemID: item.ItemID, Datacenter: datacenter})
+			} else {
+				e.emit(Event{Type: EventCheckoutFailed, CartID: cart.CartID, ItemID: item.ItemID, Datacenter: datacenter, Err: err})
+			}
+
+			err = k.RemoveItemConfigurationWithContext(ctx, cart.CartID, item.ItemID, resp.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// emit reports the event to the OnEvent callback, when set.
+func (e *Executor) emit(event Event) {
+	if e.OnEvent != nil {
+		e.OnEvent(event)
+	}
+}
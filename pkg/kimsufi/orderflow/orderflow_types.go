@@ -0,0 +1,89 @@
+package orderflow
+
+import (
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+)
+
+const (
+	// AnyOption is used to include all the datacenters or options.
+	AnyOption = "any"
+)
+
+// ConfigurationFunc returns the required configurations which could not be set automatically,
+// mergedConfigs are the configurations already set.
+type ConfigurationFunc func(mergedConfigs kimsufiorder.ItemConfigurationRequests, requiredConfigs []kimsufiorder.ItemConfiguration) (kimsufiorder.ItemConfigurationRequests, error)
+
+// OrderRequest holds the parameters of an order.
+type OrderRequest struct {
+	// Endpoint is the OVH API endpoint, used to set the region configuration (e.g. ovh-eu).
+	Endpoint string
+	// Subsidiary is the OVH subsidiary the cart is created for (e.g. FR).
+	Subsidiary string
+	PlanCode   string
+	// Datacenters are tried in order until the checkout succeeds.
+	Datacenters []string
+	// Options are the item options, as family=planCode (e.g. memory=ram-64g-noecc-2133-24ska01, memory=any, any).
+	Options []string
+	// Configurations are the item configurations, as label=value (e.g. region=europe).
+	Configurations map[string]string
+	Quantity       int
+	PriceConfig    kimsufiorder.EcoItemPriceConfig
+	AutoPay        bool
+	// DryRun only creates and configures a cart, credentials are not needed.
+	DryRun      bool
+	Credentials kimsufi.Credentials
+
+	// ConfigurationFn is called to select the required configurations which could not be set automatically.
+	// When nil, missing configurations result in an error.
+	ConfigurationFn ConfigurationFunc
+}
+
+// Plan holds the order attempts computed by the Planner.
+type Plan struct {
+	// Options are the merged item options, including all the candidates of each family.
+	Options kimsufiorder.Options
+	// Combinations holds one option per family, each combination is tried in every datacenter.
+	Combinations []kimsufiorder.Options
+	Datacenters  []string
+}
+
+// Attempt is a single checkout attempt, with a combination of options in a datacenter.
+type Attempt struct {
+	Options    kimsufiorder.Options
+	Datacenter string
+}
+
+// EventType is the type of an order flow Event.
+type EventType string
+
+const (
+	EventCartCreated            EventType = "cartCreated"
+	EventItemAdded              EventType = "itemAdded"
+	EventItemConfigured         EventType = "itemConfigured"
+	EventPlanned                EventType = "planned"
+	EventDryRun                 EventType = "dryRun"
+	EventCartAssigned           EventType = "cartAssigned"
+	EventOptionSet              EventType = "optionSet"
+	EventDatacenterConfigured   EventType = "datacenterConfigured"
+	EventDatacenterNotAvailable EventType = "datacenterNotAvailable"
+	EventCheckoutFailed         EventType = "checkoutFailed"
+	EventOrderCompleted         EventType = "orderCompleted"
+)
+
+// Event reports the progress of the order flow, only the fields relevant to its Type are set.
+type Event struct {
+	Type   EventType
+	CartID string
+	ItemID int
+	// Label and Value are the item configuration or option set, for options Label is the family and Value the plan code.
+	Label      string
+	Value      string
+	Datacenter string
+	// Plan is set on EventPlanned.
+	Plan *Plan
+	// Checkout is set on EventOrderCompleted.
+	Checkout *kimsufiorder.CheckoutResponse
+	// Err is set on EventCheckoutFailed.
+	Err error
+}
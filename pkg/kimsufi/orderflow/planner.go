@@ -0,0 +1,145 @@
+package orderflow
+
+import (
+	"fmt"
+	"slices"
+
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+	kimsufiregion "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/region"
+)
+
+// Planner computes the item configurations and the attempt plan of an order.
+type Planner struct {
+	// PriceConfig is used to compare the options prices when selecting the cheapest option of a family.
+	PriceConfig kimsufiorder.EcoItemPriceConfig
+}
+
+// NewPlanner returns a Planner comparing options with the default price config.
+func NewPlanner() Planner {
+	return Planner{
+		PriceConfig: kimsufiorder.EcoItemPriceConfig{
+			Duration:    kimsufiorder.PriceDuration,
+			PricingMode: kimsufiorder.PricingMode,
+		},
+	}
+}
+
+// Configurations returns the item configurations to set,
+// from the request configurations merged with the region and the auto configurations.
+// Required configurations which are still missing are selected using the request ConfigurationFn.
+// The datacenter is left out, it is configured for each attempt from the request datacenters,
+// so it is neither prompted for nor required when ordering without ConfigurationFn.
+func (p Planner) Configurations(req OrderRequest, autoConfigs kimsufiorder.ItemConfigurationRequests, requiredConfigs []kimsufiorder.ItemConfiguration) (kimsufiorder.ItemConfigurationRequests, error) {
+	requiredConfigs = slices.DeleteFunc(slices.Clone(requiredConfigs), func(c kimsufiorder.ItemConfiguration) bool {
+		return c.Label == kimsufiorder.ConfigurationLabelDatacenter
+	})
+	autoConfigs = slices.DeleteFunc(slices.Clone(autoConfigs), func(c kimsufiorder.ItemConfigurationRequest) bool {
+		return c.Label == kimsufiorder.ConfigurationLabelDatacenter
+	})
+
+	itemConfigurations := kimsufiorder.NewItemConfigurationsFromMap(req.Configurations)
+	r := kimsufiregion.GetRegionFromEndpoint(req.Endpoint)
+	if r != nil {
+		itemConfigurations.Add(kimsufiorder.ConfigurationLabelRegion, r.Region)
+	}
+
+	userConfigs := autoConfigs.Merge(itemConfigurations)
+
+	if req.ConfigurationFn != nil {
+		manualConfigs, err := req.ConfigurationFn(userConfigs, requiredConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return userConfigs.Merge(manualConfigs), nil
+	}
+
+	for _, option := range requiredConfigs {
+		if option.Required && userConfigs.GetByLabel(option.Label) == nil {
+			return nil, fmt.Errorf("item configuration %s is required (allowed values: %v)", option.Label, option.AllowedValues)
+		}
+	}
+
+	return userConfigs, nil
+}
+
+// Plan returns the attempt plan of the order, from the request options merged with the mandatory item options.
+func (p Planner) Plan(req OrderRequest, ecoOptions kimsufiorder.EcoItemOptions) (*Plan, error) {
+	options, err := p.mergeOptions(ecoOptions, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Options:      options,
+		Combinations: kimsufiorder.NewOptionsCombinationsFromSlice(options),
+		Datacenters:  req.Datacenters,
+	}
+
+	return plan, nil
+}
+
+// Attempts returns all the checkout attempts of the plan, in the order they are tried.
+func (p Plan) Attempts() []Attempt {
+	var attempts []Attempt
+
+	for _, options := range p.Combinations {
+		for _, datacenter := range p.Datacenters {
+			attempts = append(attempts, Attempt{
+				Options:    options,
+				Datacenter: datacenter,
+			})
+		}
+	}
+
+	return attempts
+}
+
+// mergeOptions returns the item options to use, from the user options merged with the mandatory options.
+// When a family is not set by the user, the cheapest option is used.
+func (p Planner) mergeOptions(ecoOptions kimsufiorder.EcoItemOptions, itemUserOptions []string) (kimsufiorder.Options, error) {
+	if slices.Contains(itemUserOptions, AnyOption) {
+		// Get all mandatory options
+		return ecoOptions.GetMandatoryOptions(nil).ToOptions(), nil
+	}
+
+	userOptions, err := kimsufiorder.NewOptionsFromSlice(itemUserOptions)
+	if err != nil {
+		return nil, err
+	}
+	anyOptions, userOptions := userOptions.SplitByPlanCode(AnyOption)
+	anyFamilies := anyOptions.Families()
+	userFamilies := userOptions.Families()
+
+	optionFilter := func(opts kimsufiorder.EcoItemOptions, o kimsufiorder.EcoItemOption) bool {
+		// Inclue option if it is marked as any
+		if slices.Contains(anyFamilies, o.Family) {
+			return true
+		}
+
+		if !slices.Contains(userFamilies, o.Family) {
+			return true
+		}
+
+		// Include option if its family is not already included
+		current := opts.Get(o.Family)
+		if current == nil {
+			return true
+		}
+
+		newPrice := o.GetPriceByConfig(p.PriceConfig)
+		if newPrice == nil {
+			return false
+		}
+
+		currentPrice := current.GetPriceByConfig(p.PriceConfig)
+		if currentPrice == nil {
+			return false
+		}
+
+		// Include option if its price is lower than the current one
+		return newPrice.PriceInUcents < currentPrice.PriceInUcents
+	}
+
+	mandatoryOptions := ecoOptions.GetMandatoryOptions(optionFilter)
+	return userOptions.Merge(mandatoryOptions.ToOptions()), nil
+}
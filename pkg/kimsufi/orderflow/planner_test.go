@@ -0,0 +1,185 @@
+package orderflow
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+)
+
+func ecoOption(family, planCode string, price int) kimsufiorder.EcoItemOption {
+	return kimsufiorder.EcoItemOption{
+		Option:    kimsufiorder.Option{Family: family, PlanCode: planCode},
+		Mandatory: true,
+		Prices: []kimsufiorder.EcoItemOptionPrice{
+			{Duration: kimsufiorder.PriceDuration, PricingMode: kimsufiorder.PricingMode, PriceInUcents: price},
+		},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ecoOptions := kimsufiorder.EcoItemOptions{
+		ecoOption("memory", "ram-32g-24ska01", 0),
+		ecoOption("memory", "ram-64g-24ska01", 500),
+		ecoOption("storage", "softraid-2x2000sa-24ska01", 0),
+	}
+
+	testCases := []struct {
+		name     string
+		options  []string
+		expected []Attempt
+	}{
+		{
+			name:    "any",
+			options: []string{AnyOption},
+			expected: []Attempt{
+				{
+					Options:    kimsufiorder.Options{{Family: "memory", PlanCode: "ram-32g-24ska01"}, {Family: "storage", PlanCode: "softraid-2x2000sa-24ska01"}},
+					Datacenter: "gra",
+				},
+				{
+					Options:    kimsufiorder.Options{{Family: "memory", PlanCode: "ram-32g-24ska01"}, {Family: "storage", PlanCode: "softraid-2x2000sa-24ska01"}},
+					Datacenter: "rbx",
+				},
+				{
+					Options:    kimsufiorder.Options{{Family: "memory", PlanCode: "ram-64g-24ska01"}, {Family: "storage", PlanCode: "softraid-2x2000sa-24ska01"}},
+					Datacenter: "gra",
+				},
+				{
+					Options:    kimsufiorder.Options{{Family: "memory", PlanCode: "ram-64g-24ska01"}, {Family: "storage", PlanCode: "softraid-2x2000sa-24ska01"}},
+					Datacenter: "rbx",
+				},
+			},
+		},
+		{
+			name:    "user option",
+			options: []string{"memory=ram-64g-24ska01"},
+			expected: []Attempt{
+				{
+					Options:    kimsufiorder.Options{{Family: "memory", PlanCode: "ram-64g-24ska01"}, {Family: "storage", PlanCode: "softraid-2x2000sa-24ska01"}},
+					Datacenter: "gra",
+				},
+				{
+					Options:    kimsufiorder.Options{{Family: "memory", PlanCode: "ram-64g-24ska01"}, {Family: "storage", PlanCode: "softraid-2x2000sa-24ska01"}},
+					Datacenter: "rbx",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := OrderRequest{
+				Datacenters: []string{"gra", "rbx"},
+				Options:     tc.options,
+			}
+
+			plan, err := NewPlanner().Plan(req, ecoOptions)
+			if err != nil {
+				t.Fatalf("Plan failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, plan.Attempts(), cmp.Transformer("sortOptions", sortOptions)); diff != "" {
+				t.Errorf("Attempts() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanInvalidOption(t *testing.T) {
+	_, err := NewPlanner().Plan(OrderRequest{Options: []string{"memory"}}, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestConfigurations(t *testing.T) {
+	required := []kimsufiorder.ItemConfiguration{
+		{Label: "dedicated_os", Required: true, AllowedValues: []string{"none_64.en"}},
+		{Label: kimsufiorder.ConfigurationLabelRegion, Required: true, AllowedValues: []string{"europe", "canada"}},
+		{Label: "dedicated_foo", Required: true, AllowedValues: []string{"a", "b"}},
+		{Label: kimsufiorder.ConfigurationLabelDatacenter, Required: true, AllowedValues: []string{"gra"}},
+	}
+	autoConfigs := kimsufiorder.ItemConfigurationRequests{
+		{Label: "dedicated_os", Value: "none_64.en"},
+		{Label: kimsufiorder.ConfigurationLabelDatacenter, Value: "gra"},
+	}
+
+	t.Run("missing required", func(t *testing.T) {
+		req := OrderRequest{Endpoint: "ovh-eu"}
+
+		_, err := NewPlanner().Configurations(req, autoConfigs, required)
+		if err == nil || !strings.Contains(err.Error(), "item configuration dedicated_foo is required") {
+			t.Fatalf("expected missing configuration error, got %v", err)
+		}
+	})
+
+	t.Run("configuration func", func(t *testing.T) {
+		req := OrderRequest{
+			Endpoint: "ovh-eu",
+			ConfigurationFn: func(mergedConfigs kimsufiorder.ItemConfigurationRequests, requiredConfigs []kimsufiorder.ItemConfiguration) (kimsufiorder.ItemConfigurationRequests, error) {
+				return kimsufiorder.ItemConfigurationRequests{{Label: "dedicated_foo", Value: "b"}}, nil
+			},
+		}
+
+		expected := kimsufiorder.ItemConfigurationRequests{
+			{Label: "dedicated_os", Value: "none_64.en"},
+			{Label: kimsufiorder.ConfigurationLabelRegion, Value: "europe"},
+			{Label: "dedicated_foo", Value: "b"},
+		}
+
+		actual, err := NewPlanner().Configurations(req, autoConfigs, required)
+		if err != nil {
+			t.Fatalf("Configurations failed: %v", err)
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Errorf("Configurations() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("datacenter left out", func(t *testing.T) {
+		required := []kimsufiorder.ItemConfiguration{
+			{Label: kimsufiorder.ConfigurationLabelDatacenter, Required: true, AllowedValues: []string{"gra", "rbx"}},
+		}
+
+		var prompted []kimsufiorder.ItemConfiguration
+		req := OrderRequest{
+			Endpoint: "ovh-eu",
+			ConfigurationFn: func(mergedConfigs kimsufiorder.ItemConfigurationRequests, requiredConfigs []kimsufiorder.ItemConfiguration) (kimsufiorder.ItemConfigurationRequests, error) {
+				prompted = requiredConfigs
+				return nil, nil
+			},
+		}
+
+		actual, err := NewPlanner().Configurations(req, autoConfigs, required)
+		if err != nil {
+			t.Fatalf("Configurations failed: %v", err)
+		}
+		if len(prompted) != 0 {
+			t.Errorf("expected no configuration to prompt for, got %v", prompted)
+		}
+		if c := actual.GetByLabel(kimsufiorder.ConfigurationLabelDatacenter); c != nil {
+			t.Errorf("expected no datacenter configuration, got %v", c)
+		}
+
+		// Without ConfigurationFn the datacenter is not required either
+		req.ConfigurationFn = nil
+		_, err = NewPlanner().Configurations(req, autoConfigs, required)
+		if err != nil {
+			t.Errorf("Configurations failed: %v", err)
+		}
+	})
+}
+
+// sortOptions orders options by family, as combinations are built from a map.
+func sortOptions(in kimsufiorder.Options) kimsufiorder.Options {
+	out := slices.Clone(in)
+	slices.SortFunc(out, func(a, b kimsufiorder.Option) int {
+		return strings.Compare(a.Family, b.Family)
+	})
+	return out
+}
@@ -0,0 +1,158 @@
+package ovhfake
+
+import (
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
+
+const (
+	// AnyDatacenter matches all the datacenters in Checkout.NotAvailableIn.
+	AnyDatacenter = "any"
+
+	currencyDefault = "EUR"
+)
+
+// Scenario is the scriptable state of the fake OVH API.
+type Scenario struct {
+	// Currency of the catalog prices, defaults to EUR.
+	Currency string `json:"currency" yaml:"currency"`
+	// TaxRate is the catalog tax rate in percent (e.g. 20).
+	TaxRate  int      `json:"taxRate" yaml:"taxRate"`
+	Plans    []Plan   `json:"plans" yaml:"plans"`
+	Checkout Checkout `json:"checkout" yaml:"checkout"`
+}
+
+// Plan is a server plan with a single memory and storage configuration.
+type Plan struct {
+	PlanCode    string `json:"planCode" yaml:"planCode"`
+	InvoiceName string `json:"invoiceName" yaml:"invoiceName"`
+	// Memory and Storage are the addons generic names (e.g. ram-32g-noecc-2133),
+	// the addons plan codes are suffixed with the plan code.
+	Memory  string `json:"memory" yaml:"memory"`
+	Storage string `json:"storage" yaml:"storage"`
+	// Price is the monthly price (e.g. 12.99).
+	Price float64 `json:"price" yaml:"price"`
+	// SetupFee is the installation price, charged once.
+	SetupFee    float64      `json:"setupFee" yaml:"setupFee"`
+	Commitments []Commitment `json:"commitments" yaml:"commitments"`
+	Datacenters []Datacenter `json:"datacenters" yaml:"datacenters"`
+	// Technical holds the hardware specifications, reported in the technical blobs
+	// of the plan product (CPU) and of the memory, storage and bandwidth addons products.
+	Technical kimsuficatalog.ProductBlobsTechnical `json:"technical" yaml:"technical"`
+}
+
+// Commitment is a price of a plan with a commitment.
+type Commitment struct {
+	// Months is the commitment duration.
+	Months int `json:"months" yaml:"months"`
+	// Upfront charges the whole commitment at once, otherwise it is charged monthly at a degressive price.
+	Upfront bool `json:"upfront" yaml:"upfront"`
+	// Price is the monthly price (e.g. 11.99).
+	Price float64 `json:"price" yaml:"price"`
+}
+
+// Datacenter scripts the availability of a plan in a datacenter.
+type Datacenter struct {
+	Datacenter string `json:"datacenter" yaml:"datacenter"`
+	// AvailableAfter is the number of availability polls of the plan
+	// before it becomes available in this datacenter, 0 means available right away.
+	AvailableAfter int `json:"availableAfter" yaml:"availableAfter"`
+	// Unavailable keeps the plan unavailable in this datacenter.
+	Unavailable bool `json:"unavailable" yaml:"unavailable"`
+}
+
+// Checkout scripts the cart checkout.
+type Checkout struct {
+	// NotAvailableIn lists the datacenters where the checkout fails with an "is not available in" error,
+	// even when the plan is available, use AnyDatacenter to fail in all datacenters.
+	NotAvailableIn []string `json:"notAvailableIn" yaml:"notAvailableIn"`
+}
+
+// DefaultScenario returns a scenario with a plan available in gra and rbx with commitment prices,
+// and a plan with a setup fee which becomes available in bhs after 3 polls.
+func DefaultScenario() Scenario {
+	return Scenario{
+		Currency: currencyDefault,
+		TaxRate:  20,
+		Plans: []Plan{
+			{
+				PlanCode:    "24ska01",
+				InvoiceName: "KS-A | Intel i7-6700k",
+				Memory:      "ram-32g-noecc-2133",
+				Storage:     "softraid-2x2000sa",
+				Price:       12.99,
+				Commitments: []Commitment{
+					{Months: 12, Upfront: true, Price: 11.99},
+					{Months: 24, Price: 10.99},
+				},
+				Datacenters: []Datacenter{
+					{Datacenter: "gra"},
+					{Datacenter: "rbx"},
+					{Datacenter: "sbg", Unavailable: true},
+				},
+				Technical: kimsuficatalog.ProductBlobsTechnical{
+					Bandwidth: kimsuficatalog.ProductBlobsTechnicalBandwidth{Level: 100},
+					Memory:    kimsuficatalog.ProductBlobsTechnicalMemory{Frequency: 2133, RAMType: "DDR4", Size: 32},
+					Server: kimsuficatalog.ProductBlobsTechnicalServer{
+						CPU: kimsuficatalog.ProductBlobsTechnicalCPU{Brand: "Intel", Model: "i7-6700k", Cores: 4, Threads: 8, Frequency: 4, Number: 1},
+					},
+					Storage: kimsuficatalog.ProductBlobsTechnicalStorage{
+						Disks: []kimsuficatalog.ProductBlobsTechnicalStorageDisk{{Number: 2, Capacity: 2000, Interface: "SATA", Technology: "HDD"}},
+						Raid:  "soft",
+					},
+				},
+			},
+			{
+				PlanCode:    "24sk10",
+				InvoiceName: "KS-1 | Intel Xeon-D 1520",
+				Memory:      "ram-32g-ecc-2133",
+				Storage:     "softraid-2x450nvme",
+				Price:       14.99,
+				SetupFee:    9.99,
+				Datacenters: []Datacenter{
+					{Datacenter: "bhs", AvailableAfter: 3},
+				},
+				Technical: kimsuficatalog.ProductBlobsTechnical{
+					Bandwidth: kimsuficatalog.ProductBlobsTechnicalBandwidth{Level: 100},
+					Memory:    kimsuficatalog.ProductBlobsTechnicalMemory{ECC: true, Frequency: 2133, RAMType: "DDR4", Size: 32},
+					Server: kimsuficatalog.ProductBlobsTechnicalServer{
+						CPU: kimsuficatalog.ProductBlobsTechnicalCPU{Brand: "Intel", Model: "Xeon-D 1520", Cores: 4, Threads: 8, Frequency: 2.2, Number: 1},
+					},
+					Storage: kimsuficatalog.ProductBlobsTechnicalStorage{
+						Disks: []kimsuficatalog.ProductBlobsTechnicalStorageDisk{{Number: 2, Capacity: 450, Interface: "NVMe", Technology: "SSD"}},
+						Raid:  "soft",
+					},
+				},
+			},
+		},
+	}
+}
+
+// available returns whether the datacenter is available after the given number of polls.
+func (d Datacenter) available(polls int) bool {
+	return !d.Unavailable && polls >= d.AvailableAfter
+}
+
+// LoadScenario reads a YAML scenario file.
+func LoadScenario(path string) (Scenario, error) {
+	var s Scenario
+
+	f, err := os.Open(path)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close() // nolint:errcheck
+
+	d := yaml.NewDecoder(f)
+	d.KnownFields(true)
+	err = d.Decode(&s)
+	if err != nil {
+		return s, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	return s, nil
+}
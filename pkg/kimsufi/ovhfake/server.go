@@ -0,0 +1,710 @@
+// Package ovhfake implements a fake OVH API, serving the endpoints used by kimsufi.Service
+// from a scriptable Scenario, for tests and local development.
+package ovhfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	kimsufiauthentication "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/authentication"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+)
+
+const (
+	availabilityDefault = "1H-high"
+
+	configurationLabelOS = "dedicated_os"
+	configurationValueOS = "none_64.en"
+
+	pricingCapacityInstallation = "installation"
+)
+
+// Server is a fake OVH API http.Handler.
+// It is safe for concurrent use.
+type Server struct {
+	mux      *http.ServeMux
+	scenario Scenario
+
+	mu     sync.Mutex
+	polls  map[string]int
+	carts  map[string]*cart
+	nextID int
+	orders []Order
+}
+
+// Order is an order completed by a cart checkout.
+type Order struct {
+	OrderID    int
+	CartID     string
+	PlanCode   string
+	Datacenter string
+	Options    []string
+	AutoPay    bool
+}
+
+type cart struct {
+	subsidiary string
+	assigned   bool
+	items      map[int]*item
+}
+
+type item struct {
+	planCode       string
+	options        []string
+	configurations map[int]kimsufiorder.ItemConfigurationResponse
+}
+
+// New returns a Server serving the given scenario.
+func New(scenario Scenario) *Server {
+	if scenario.Currency == "" {
+		scenario.Currency = currencyDefault
+	}
+
+	s := &Server{
+		mux:      http.NewServeMux(),
+		scenario: scenario,
+		polls:    make(map[string]int),
+		carts:    make(map[string]*cart),
+	}
+
+	s.mux.HandleFunc("GET /auth/time", s.getTime)
+	s.mux.HandleFunc("GET /auth/details", s.getAuthDetails)
+	s.mux.HandleFunc("GET /auth/currentCredential", s.getCurrentCredential)
+	s.mux.HandleFunc("GET /dedicated/server/datacenter/availabilities", s.getAvailabilities)
+	s.mux.HandleFunc("GET /order/catalog/public/eco", s.getCatalog)
+	s.mux.HandleFunc("POST /order/cart", s.createCart)
+	s.mux.HandleFunc("POST /order/cart/{cartID}/eco", s.addEcoItem)
+	s.mux.HandleFunc("GET /order/cart/{cartID}/eco", s.getEcoInfo)
+	s.mux.HandleFunc("GET /order/cart/{cartID}/eco/options", s.getEcoOptions)
+	s.mux.HandleFunc("POST /order/cart/{cartID}/eco/options", s.addEcoOption)
+	s.mux.HandleFunc("GET /order/cart/{cartID}/item/{itemID}/requiredConfiguration", s.getRequiredConfiguration)
+	s.mux.HandleFunc("POST /order/cart/{cartID}/item/{itemID}/configuration", s.addConfiguration)
+	s.mux.HandleFunc("DELETE /order/cart/{cartID}/item/{itemID}/configuration/{configurationID}", s.removeConfiguration)
+	s.mux.HandleFunc("POST /order/cart/{cartID}/assign", s.assignCart)
+	s.mux.HandleFunc("POST /order/cart/{cartID}/checkout", s.checkout)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Polls returns the number of availability polls of the plan.
+func (s *Server) Polls(planCode string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.polls[planCode]
+}
+
+// Orders returns the orders completed so far.
+func (s *Server) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.orders)
+}
+
+func (s *Server) getTime(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, time.Now().Unix())
+}
+
+func (s *Server) getAuthDetails(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{})
+}
+
+func (s *Server) getCurrentCredential(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+
+	writeJSON(w, kimsufiauthentication.CurrentCredentialResponse{
+		ApplicationID: 1,
+		CredentialID:  1,
+		Creation:      now.Format(time.RFC3339),
+		Expiration:    now.AddDate(0, 0, 1).Format(time.RFC3339),
+		LastUse:       now.Format(time.RFC3339),
+		Rules: []kimsufiauthentication.CurrentCredentialRule{
+			{Method: http.MethodGet, Path: "/*"},
+			{Method: http.MethodPost, Path: "/*"},
+		},
+		Status: "validated",
+	})
+}
+
+// getAvailabilities counts a poll for every plan matching the query.
+func (s *Server) getAvailabilities(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var datacenters []string
+	if q.Get("datacenters") != "" {
+		datacenters = strings.Split(q.Get("datacenters"), ",")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	availabilities := kimsufiavailability.Availabilities{}
+	for _, p := range s.scenario.Plans {
+		if q.Get("planCode") != "" && q.Get("planCode") != p.PlanCode ||
+			q.Get("memory") != "" && q.Get("memory") != p.Memory ||
+			q.Get("storage") != "" && q.Get("storage") != p.Storage {
+			continue
+		}
+
+		polls := s.polls[p.PlanCode]
+		s.polls[p.PlanCode]++
+
+		a := kimsufiavailability.Availability{
+			FQN:      fmt.Sprintf("%s.%s.%s", p.PlanCode, p.Memory, p.Storage),
+			Memory:   p.Memory,
+			PlanCode: p.PlanCode,
+			Server:   p.PlanCode,
+			Storage:  p.Storage,
+		}
+		for _, d := range p.Datacenters {
+			if len(datacenters) > 0 && !slices.Contains(datacenters, d.Datacenter) {
+				continue
+			}
+
+			availability := kimsufiavailability.StatusUnavailable
+			if d.available(polls) {
+				availability = availabilityDefault
+			}
+
+			a.Datacenters = append(a.Datacenters, kimsufiavailability.Datacenter{
+				Datacenter:   d.Datacenter,
+				Availability: availability,
+			})
+		}
+
+		availabilities = append(availabilities, a)
+	}
+
+	if len(availabilities) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No availabilities found for planCode %s", q.Get("planCode")))
+		return
+	}
+
+	writeJSON(w, availabilities)
+}
+
+func (s *Server) getCatalog(w http.ResponseWriter, r *http.Request) {
+	catalog := kimsuficatalog.Catalog{
+		CatalogID: 1,
+		Locale: kimsuficatalog.Locale{
+			CurrencyCode: s.scenario.Currency,
+			Subsidiary:   r.URL.Query().Get("ovhSubsidiary"),
+			TaxRate:      s.scenario.TaxRate,
+		},
+		Plans:    []kimsuficatalog.Plan{},
+		Addons:   []kimsuficatalog.Addon{},
+		Products: []kimsuficatalog.Product{},
+	}
+
+	for _, p := range s.scenario.Plans {
+		var datacenters []string
+		for _, d := range p.Datacenters {
+			datacenters = append(datacenters, d.Datacenter)
+		}
+
+		plan := kimsuficatalog.Plan{
+			InvoiceName: p.InvoiceName,
+			PlanCode:    p.PlanCode,
+			Product:     p.PlanCode,
+			PricingType: kimsufiorder.PricingType,
+			Pricings:    pricings(p),
+			Configurations: []kimsuficatalog.PlanConfiguration{
+				{Name: kimsufiorder.ConfigurationLabelDatacenter, IsMandatory: true, Values: datacenters},
+				{Name: configurationLabelOS, IsMandatory: true, Values: []string{configurationValueOS}},
+			},
+		}
+
+		for _, o := range addons(p) {
+			plan.AddonFamilies = append(plan.AddonFamilies, kimsuficatalog.PlanAddonFamily{
+				Addons:    []string{o.planCode},
+				Default:   o.planCode,
+				Exclusive: true,
+				Mandatory: true,
+				Name:      o.family,
+			})
+			catalog.Addons = append(catalog.Addons, kimsuficatalog.Addon{
+				InvoiceName: o.genericName,
+				PlanCode:    o.planCode,
+				PricingType: kimsufiorder.PricingType,
+				Pricings:    []kimsuficatalog.PlanPricing{monthlyPricing(0)},
+				Product:     o.genericName,
+			})
+			catalog.Products = append(catalog.Products, kimsuficatalog.Product{
+				Blobs:       kimsuficatalog.ProductBlobs{Technical: o.technical},
+				Description: o.genericName,
+				Name:        o.genericName,
+			})
+		}
+
+		catalog.Products = append(catalog.Products, kimsuficatalog.Product{
+			Blobs: kimsuficatalog.ProductBlobs{
+				Technical: kimsuficatalog.ProductBlobsTechnical{Server: p.Technical.Server},
+			},
+			Description: p.InvoiceName,
+			Name:        p.PlanCode,
+		})
+
+		catalog.Plans = append(catalog.Plans, plan)
+	}
+
+	writeJSON(w, catalog)
+}
+
+func (s *Server) createCart(w http.ResponseWriter, r *http.Request) {
+	var req kimsufiorder.CartRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	cartID := fmt.Sprintf("fake-cart-%d", s.nextID)
+	s.carts[cartID] = &cart{
+		subsidiary: req.OvhSubsidiary,
+		items:      make(map[int]*item),
+	}
+
+	writeJSON(w, kimsufiorder.CartResponse{CartID: cartID})
+}
+
+func (s *Server) addEcoItem(w http.ResponseWriter, r *http.Request) {
+	var req kimsufiorder.EcoItemRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCart(w, r)
+	if c == nil {
+		return
+	}
+
+	if s.getPlan(req.PlanCode) == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Plan %s not found", req.PlanCode))
+		return
+	}
+
+	s.nextID++
+	c.items[s.nextID] = &item{
+		planCode:       req.PlanCode,
+		configurations: make(map[int]kimsufiorder.ItemConfigurationResponse),
+	}
+
+	writeJSON(w, kimsufiorder.EcoItemResponse{CartID: r.PathValue("cartID"), ItemID: s.nextID})
+}
+
+func (s *Server) getEcoInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.getCart(w, r) == nil {
+		return
+	}
+
+	p := s.getPlan(r.URL.Query().Get("planCode"))
+	if p == nil {
+		writeJSON(w, kimsufiorder.EcoItemInfos{})
+		return
+	}
+
+	writeJSON(w, kimsufiorder.EcoItemInfos{
+		{
+			PlanCode:    p.PlanCode,
+			ProductName: p.InvoiceName,
+			Prices: []kimsufiorder.EcoItemInfoPrice{
+				{
+					Duration:    kimsufiorder.PriceDuration,
+					PricingMode: kimsufiorder.PricingMode,
+					Price:       price(p.Price, s.scenario.Currency),
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) getEcoOptions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.getCart(w, r) == nil {
+		return
+	}
+
+	options := kimsufiorder.EcoItemOptions{}
+	p := s.getPlan(r.URL.Query().Get("planCode"))
+	if p != nil {
+		for _, o := range addons(*p) {
+			options = append(options, kimsufiorder.EcoItemOption{
+				Option:    kimsufiorder.Option{Family: o.family, PlanCode: o.planCode},
+				Mandatory: true,
+				Prices: []kimsufiorder.EcoItemOptionPrice{
+					{
+						Duration:    kimsufiorder.PriceDuration,
+						PricingMode: kimsufiorder.PricingMode,
+						Price:       price(0, s.scenario.Currency),
+					},
+				},
+				ProductName: o.genericName,
+			})
+		}
+	}
+
+	writeJSON(w, options)
+}
+
+func (s *Server) addEcoOption(w http.ResponseWriter, r *http.Request) {
+	var req kimsufiorder.EcoItemOptionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCart(w, r)
+	if c == nil {
+		return
+	}
+
+	i, found := c.items[req.ItemID]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Item %d not found", req.ItemID))
+		return
+	}
+	i.options = append(i.options, req.PlanCode)
+
+	writeJSON(w, req)
+}
+
+func (s *Server) getRequiredConfiguration(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, i := s.getItem(w, r)
+	if i == nil {
+		return
+	}
+
+	var datacenters []string
+	for _, d := range s.getPlan(i.planCode).Datacenters {
+		datacenters = append(datacenters, d.Datacenter)
+	}
+
+	writeJSON(w, []kimsufiorder.ItemConfiguration{
+		{Label: kimsufiorder.ConfigurationLabelDatacenter, Required: true, Type: "string", AllowedValues: datacenters},
+		{Label: configurationLabelOS, Required: true, Type: "string", AllowedValues: []string{configurationValueOS}},
+	})
+}
+
+func (s *Server) addConfiguration(w http.ResponseWriter, r *http.Request) {
+	var req kimsufiorder.ItemConfigurationRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, i := s.getItem(w, r)
+	if i == nil {
+		return
+	}
+
+	for _, c := range i.configurations {
+		if c.Label == req.Label {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Configuration %s already set", req.Label))
+			return
+		}
+	}
+
+	s.nextID++
+	resp := kimsufiorder.ItemConfigurationResponse{
+		ItemConfigurationRequest: req,
+		ID:                       s.nextID,
+	}
+	i.configurations[resp.ID] = resp
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) removeConfiguration(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, i := s.getItem(w, r)
+	if i == nil {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("configurationID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid configuration ID %s", r.PathValue("configurationID")))
+		return
+	}
+	delete(i.configurations, id)
+
+	writeJSON(w, nil)
+}
+
+func (s *Server) assignCart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCart(w, r)
+	if c == nil {
+		return
+	}
+	c.assigned = true
+
+	writeJSON(w, nil)
+}
+
+// checkout completes the order when every item datacenter is available,
+// it fails with an "is not available in" error otherwise.
+func (s *Server) checkout(w http.ResponseWriter, r *http.Request) {
+	var req kimsufiorder.CheckoutRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCart(w, r)
+	if c == nil {
+		return
+	}
+
+	if !c.assigned {
+		writeError(w, http.StatusForbidden, "Cart is not assigned to an account")
+		return
+	}
+
+	var orders []Order
+	for _, i := range c.items {
+		var datacenter string
+		for _, configuration := range i.configurations {
+			if configuration.Label == kimsufiorder.ConfigurationLabelDatacenter {
+				datacenter = configuration.Value
+			}
+		}
+		if datacenter == "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Missing configuration %s for item", kimsufiorder.ConfigurationLabelDatacenter))
+			return
+		}
+
+		if !s.checkoutAvailable(i.planCode, datacenter) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Product %s is not available in %s", i.planCode, datacenter))
+			return
+		}
+
+		orders = append(orders, Order{
+			CartID:     r.PathValue("cartID"),
+			PlanCode:   i.planCode,
+			Datacenter: datacenter,
+			Options:    slices.Clone(i.options),
+			AutoPay:    req.AutoPayWithPreferredPaymentMethod,
+		})
+	}
+
+	s.nextID++
+	for _, o := range orders {
+		o.OrderID = s.nextID
+		s.orders = append(s.orders, o)
+	}
+	delete(s.carts, r.PathValue("cartID"))
+
+	writeJSON(w, kimsufiorder.CheckoutResponse{
+		OrderID: s.nextID,
+		URL:     fmt.Sprintf("https://www.ovh.com/cgi-bin/order/display-order.cgi?orderId=%d&orderPassword=fake", s.nextID),
+	})
+}
+
+// checkoutAvailable returns whether the plan can be ordered in the datacenter,
+// according to the last availability poll and the scenario checkout.
+func (s *Server) checkoutAvailable(planCode, datacenter string) bool {
+	notAvailableIn := s.scenario.Checkout.NotAvailableIn
+	if slices.Contains(notAvailableIn, AnyDatacenter) || slices.Contains(notAvailableIn, datacenter) {
+		return false
+	}
+
+	p := s.getPlan(planCode)
+	if p == nil {
+		return false
+	}
+
+	for _, d := range p.Datacenters {
+		if d.Datacenter == datacenter {
+			return d.available(max(s.polls[planCode]-1, 0))
+		}
+	}
+
+	return false
+}
+
+// getCart returns the cart from the request path, or writes a not found error.
+func (s *Server) getCart(w http.ResponseWriter, r *http.Request) *cart {
+	c, found := s.carts[r.PathValue("cartID")]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Cart %s not found", r.PathValue("cartID")))
+		return nil
+	}
+
+	return c
+}
+
+// getItem returns the cart and item from the request path, or writes a not found error.
+func (s *Server) getItem(w http.ResponseWriter, r *http.Request) (*cart, *item) {
+	c := s.getCart(w, r)
+	if c == nil {
+		return nil, nil
+	}
+
+	id, _ := strconv.Atoi(r.PathValue("itemID"))
+	i, found := c.items[id]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Item %s not found", r.PathValue("itemID")))
+		return nil, nil
+	}
+
+	return c, i
+}
+
+func (s *Server) getPlan(planCode string) *Plan {
+	for index := range s.scenario.Plans {
+		p := &s.scenario.Plans[index]
+		if p.PlanCode == planCode {
+			return p
+		}
+	}
+
+	return nil
+}
+
+type addon struct {
+	family      string
+	genericName string
+	planCode    string
+	technical   kimsuficatalog.ProductBlobsTechnical
+}
+
+// addons returns the mandatory addons of the plan, their plan codes are suffixed with the plan code.
+func addons(p Plan) []addon {
+	return []addon{
+		{
+			family:      kimsuficatalog.AddonMemory,
+			genericName: p.Memory,
+			planCode:    p.Memory + "-" + p.PlanCode,
+			technical:   kimsuficatalog.ProductBlobsTechnical{Memory: p.Technical.Memory},
+		},
+		{
+			family:      kimsuficatalog.AddonStorage,
+			genericName: p.Storage,
+			planCode:    p.Storage + "-" + p.PlanCode,
+			technical:   kimsuficatalog.ProductBlobsTechnical{Storage: p.Technical.Storage},
+		},
+		{
+			family:      kimsuficatalog.AddonBandwidth,
+			genericName: "bandwidth-100",
+			planCode:    "bandwidth-100-" + p.PlanCode,
+			technical:   kimsuficatalog.ProductBlobsTechnical{Bandwidth: p.Technical.Bandwidth},
+		},
+	}
+}
+
+// monthlyPricing returns the default monthly catalog pricing, catalog prices are expressed in 10^-8 units.
+func monthlyPricing(value float64) kimsuficatalog.PlanPricing {
+	return kimsuficatalog.PlanPricing{
+		Capacities:   []string{kimsufiorder.PricingCapacityRenew},
+		Interval:     1,
+		IntervalUnit: "month",
+		Mode:         kimsuficatalog.PriceModeDefault,
+		Phase:        1,
+		Price:        int(math.Round(value * 1e8)),
+		Quantity:     kimsuficatalog.PlanPricingMinMax{Min: 1, Max: 1},
+		Repeat:       kimsuficatalog.PlanPricingMinMax{Min: 1, Max: 1},
+		Strategy:     "tiered",
+		Type:         kimsufiorder.PricingType,
+	}
+}
+
+// pricings returns the catalog pricings of the plan: setup fee, monthly price and commitment prices.
+func pricings(p Plan) []kimsuficatalog.PlanPricing {
+	var pricings []kimsuficatalog.PlanPricing
+	if p.SetupFee > 0 {
+		setupFee := monthlyPricing(p.SetupFee)
+		setupFee.Capacities = []string{pricingCapacityInstallation}
+		setupFee.Interval = 0
+		setupFee.IntervalUnit = "none"
+		pricings = append(pricings, setupFee)
+	}
+
+	pricings = append(pricings, monthlyPricing(p.Price))
+
+	for _, c := range p.Commitments {
+		pricing := monthlyPricing(c.Price)
+		pricing.Commitement = c.Months
+		pricing.Mode = fmt.Sprintf("degressivity%d", c.Months)
+		if c.Upfront {
+			pricing.Interval = c.Months
+			pricing.Mode = fmt.Sprintf("upfront%d", c.Months)
+			pricing.Price = int(math.Round(c.Price * float64(c.Months) * 1e8))
+		}
+		pricings = append(pricings, pricing)
+	}
+
+	return pricings
+}
+
+func price(value float64, currency string) kimsufiorder.Price {
+	return kimsufiorder.Price{
+		CurrencyCode:  currency,
+		PriceInUcents: int(math.Round(value * 1e8)),
+		Text:          fmt.Sprintf("%.2f %s", value, currency),
+		Value:         value,
+	}
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err))
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v) // nolint:errcheck
+}
+
+// writeError writes an error in the OVH API format, see ovh.APIError.
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{ // nolint:errcheck
+		"class":   "Client::" + strings.ReplaceAll(http.StatusText(code), " ", ""),
+		"message": message,
+	})
+}
@@ -0,0 +1,148 @@
+package ovhfake
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiorder "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/order"
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/orderflow"
+)
+
+func newService(t *testing.T, scenario Scenario) (*kimsufi.Service, *Server) {
+	t.Helper()
+
+	fake := New(scenario)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	k, err := kimsufi.NewService(server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("NewService failed: %v", err)
+	}
+
+	return k, fake
+}
+
+func TestAvailabilitiesAfterPolls(t *testing.T) {
+	k, fake := newService(t, DefaultScenario())
+
+	var actual [][]string
+	for range 5 {
+		availabilities, err := k.GetAvailabilities(nil, "24sk10", nil)
+		if err != nil {
+			t.Fatalf("GetAvailabilities failed: %v", err)
+		}
+
+		actual = append(actual, (*availabilities)[0].GetAvailableDatacenters().Codes())
+	}
+
+	expected := [][]string{nil, nil, nil, {"bhs"}, {"bhs"}}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("available datacenters mismatch (-want +got):\n%s", diff)
+	}
+
+	if fake.Polls("24sk10") != 5 {
+		t.Errorf("expected 5 polls, got %d", fake.Polls("24sk10"))
+	}
+
+	_, err := k.GetAvailabilities(nil, "unknown", nil)
+	if !kimsufi.IsAvailabilityNotFoundError(err) {
+		t.Errorf("expected availability not found error, got %v", err)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	k, _ := newService(t, DefaultScenario())
+
+	catalog, err := k.ListServers("FR")
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+
+	plan := catalog.GetPlan("24ska01")
+	if plan == nil {
+		t.Fatal("expected plan 24ska01 to be found")
+	}
+
+	if price := plan.GetFirstPrice().GetPrice(); price != 12.99 {
+		t.Errorf("expected price 12.99, got %v", price)
+	}
+
+	if addon := kimsufi.FindAddon(plan, "memory", "ram-32g-noecc-2133"); addon != "ram-32g-noecc-2133-24ska01" {
+		t.Errorf("expected memory addon ram-32g-noecc-2133-24ska01, got %q", addon)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	testCases := []struct {
+		name        string
+		checkout    Checkout
+		datacenters []string
+		expected    []Order
+	}{
+		{
+			name:        "available",
+			datacenters: []string{"gra"},
+			expected: []Order{
+				{PlanCode: "24ska01", Datacenter: "gra"},
+			},
+		},
+		{
+			name:        "first datacenter not available",
+			checkout:    Checkout{NotAvailableIn: []string{"gra"}},
+			datacenters: []string{"gra", "rbx"},
+			expected: []Order{
+				{PlanCode: "24ska01", Datacenter: "rbx"},
+			},
+		},
+		{
+			name:        "unavailable datacenter",
+			datacenters: []string{"sbg"},
+		},
+		{
+			name:        "not available anywhere",
+			checkout:    Checkout{NotAvailableIn: []string{AnyDatacenter}},
+			datacenters: []string{"gra", "rbx"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scenario := DefaultScenario()
+			scenario.Checkout = tc.checkout
+			k, fake := newService(t, scenario)
+
+			req := orderflow.OrderRequest{
+				Subsidiary:  "FR",
+				PlanCode:    "24ska01",
+				Datacenters: tc.datacenters,
+				Quantity:    kimsufiorder.QuantityDefault,
+				Credentials: kimsufi.Credentials{AppKey: "key", AppSecret: "secret", ConsumerKey: "consumer"},
+			}
+
+			resp, err := orderflow.NewExecutor(k, nil).Execute(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			orders := fake.Orders()
+			if (resp != nil) != (len(orders) > 0) {
+				t.Errorf("expected checkout response only when an order is completed, got %v", resp)
+			}
+
+			// Only compare what was ordered
+			var actual []Order
+			for _, o := range orders {
+				actual = append(actual, Order{PlanCode: o.PlanCode, Datacenter: o.Datacenter})
+			}
+
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("orders mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
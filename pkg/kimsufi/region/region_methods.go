@@ -29,3 +29,14 @@ func GetRegionFromEndpoint(endpoint string) *Region {
 
 	return nil
 }
+
+// HasCountry returns true if the country code is one of the region countries.
+func (r Region) HasCountry(country string) bool {
+	for _, c := range r.Countries {
+		if strings.EqualFold(c.Code, country) {
+			return true
+		}
+	}
+
+	return false
+}
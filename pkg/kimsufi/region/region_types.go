@@ -5,9 +5,10 @@ var (
 	// Icons are the unicode flags for each country.
 	AllowedRegions = Regions{
 		{
-			DisplayName: "Europe",
-			Region:      "europe",
-			Endpoint:    "ovh-eu",
+			DisplayName:    "Europe",
+			Region:         "europe",
+			Endpoint:       "ovh-eu",
+			DefaultCountry: "FR",
 			Countries: []Country{
 				{
 					Code: "CZ",
@@ -72,9 +73,10 @@ var (
 			},
 		},
 		{
-			DisplayName: "Other",
-			Region:      "canada",
-			Endpoint:    "ovh-ca",
+			DisplayName:    "Other",
+			Region:         "canada",
+			Endpoint:       "ovh-ca",
+			DefaultCountry: "CA",
 			Countries: []Country{
 				{
 					Code: "ASIA",
@@ -107,8 +109,9 @@ var (
 			},
 		},
 		{
-			DisplayName: "US",
-			Endpoint:    "ovh-us",
+			DisplayName:    "US",
+			Endpoint:       "ovh-us",
+			DefaultCountry: "US",
 			Countries: []Country{
 				{
 					Code: "US",
@@ -125,7 +128,9 @@ type Region struct {
 	DisplayName string
 	Region      string
 	Endpoint    string
-	Countries   []Country
+	// DefaultCountry is the country used for the region when none of its countries is given.
+	DefaultCountry string
+	Countries      []Country
 }
 
 type Country struct {
@@ -0,0 +1,211 @@
+package kimsufi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RetryPolicy defines how failed OVH API requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value lower than 2 disables retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it doubles on every retry.
+	// The actual delay is randomized between 0 and this value (full jitter).
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts.
+	// A Retry-After header asking to wait longer than MaxDelay is not honored and the response is returned.
+	MaxDelay time.Duration
+	// RetryNonIdempotent also retries the POST and DELETE requests (e.g. cart creation),
+	// which may create duplicate carts or items. The checkout is never retried.
+	RetryNonIdempotent bool
+}
+
+var (
+	// DefaultRetryPolicy retries idempotent requests up to 3 times.
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+
+	// retryableStatusCodes are the response status codes worth retrying,
+	// the API is rate limiting or temporarily unavailable.
+	retryableStatusCodes = []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+)
+
+// RetryTransport is an http.RoundTripper retrying the failed requests according to a RetryPolicy.
+type RetryTransport struct {
+	policy    RetryPolicy
+	transport http.RoundTripper
+	logger    *Logger
+
+	// sleep waits for d or until ctx is done, it is replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport returns a RetryTransport using policy.
+// transport is optional, if nil http.DefaultTransport is used.
+// logger is optional, if nil a no-op logger will be used.
+func NewRetryTransport(policy RetryPolicy, transport http.RoundTripper, logger *log.Logger) *RetryTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &RetryTransport{
+		policy:    policy,
+		transport: transport,
+		logger:    NewRequestLogger(logger),
+		sleep:     sleep,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.policy.allows(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.transport.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !isRetryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(attempt)
+		fields := log.Fields{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"attempt": attempt,
+		}
+		if err != nil {
+			fields["error"] = err
+		} else {
+			fields["status"] = resp.StatusCode
+
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if retryAfter > t.policy.MaxDelay {
+					// Waiting that long is not worth it
+					return resp, nil
+				}
+				delay = max(delay, retryAfter)
+			}
+
+			resp.Body.Close() // nolint:errcheck
+		}
+		fields["delay"] = delay
+		t.logger.WithFields(fields).Warn("retrying OVH API request")
+
+		err = t.sleep(req.Context(), delay)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rewind the request body
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// allows returns true when the request may be retried by the policy.
+func (p RetryPolicy) allows(req *http.Request) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+
+	// A request body which cannot be rewinded cannot be sent twice
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost, http.MethodDelete:
+		return p.RetryNonIdempotent && !strings.HasSuffix(req.URL.Path, "/checkout")
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the next attempt, with full jitter.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		delay = min(p.BaseDelay<<shift, p.MaxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return rand.N(delay + 1)
+}
+
+// isRetryable returns true for network errors and retryable status codes.
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// Do not retry when the caller gave up
+		return req.Context().Err() == nil && !errors.Is(err, context.Canceled)
+	}
+
+	for _, code := range retryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value,
+// given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	date, err := http.ParseTime(value)
+	if err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,256 @@
+package kimsufi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetryTransport(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+	cartPolicy := policy
+	cartPolicy.RetryNonIdempotent = true
+
+	testCases := []struct {
+		name             string
+		policy           RetryPolicy
+		method           string
+		path             string
+		statusCodes      []int
+		retryAfter       string
+		expectedStatus   int
+		expectedAttempts int
+		expectedMinDelay time.Duration
+	}{
+		{
+			name:             "success",
+			policy:           policy,
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retry server error",
+			policy:           policy,
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "give up after max attempts",
+			policy:           policy,
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusInternalServerError},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "client error is not retried",
+			policy:           policy,
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusNotFound},
+			expectedStatus:   http.StatusNotFound,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retry after",
+			policy:           policy,
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "5",
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 2,
+			expectedMinDelay: 5 * time.Second,
+		},
+		{
+			name:             "retry after longer than max delay",
+			policy:           policy,
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "3600",
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "post is not retried by default",
+			policy:           policy,
+			method:           http.MethodPost,
+			path:             "/order/cart",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "post is retried when enabled",
+			policy:           cartPolicy,
+			method:           http.MethodPost,
+			path:             "/order/cart",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "checkout is never retried",
+			policy:           cartPolicy,
+			method:           http.MethodPost,
+			path:             "/order/cart/1/checkout",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "disabled",
+			policy:           RetryPolicy{MaxAttempts: 1},
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+
+				statusCode := tc.statusCodes[min(len(bodies), len(tc.statusCodes))-1]
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(statusCode)
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			transport := NewRetryTransport(tc.policy, nil, nil)
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+			client := &http.Client{Transport: transport}
+
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(`{"planCode":"24ska01"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close() // nolint:errcheck
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, resp.StatusCode)
+			}
+
+			if len(bodies) != tc.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.expectedAttempts, len(bodies))
+			}
+
+			// The request body is sent on every attempt
+			for _, body := range bodies {
+				if body != `{"planCode":"24ska01"}` {
+					t.Errorf("expected request body to be sent, got %q", body)
+				}
+			}
+
+			for _, d := range delays {
+				if d < tc.expectedMinDelay || d > tc.policy.MaxDelay {
+					t.Errorf("expected delay between %s and %s, got %s", tc.expectedMinDelay, tc.policy.MaxDelay, d)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportContextCanceled(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport := NewRetryTransport(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}, nil, nil)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleep(ctx, d)
+	}
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Do(req)
+	if err == nil {
+		t.Error("expected context canceled error")
+	}
+
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "seconds",
+			value:    "120",
+			expected: 2 * time.Minute,
+			ok:       true,
+		},
+		{
+			name:     "http date",
+			value:    "Wed, 01 Jan 2025 00:00:30 GMT",
+			expected: 30 * time.Second,
+			ok:       true,
+		},
+		{
+			name:     "http date in the past",
+			value:    "Tue, 31 Dec 2024 23:59:00 GMT",
+			expected: 0,
+			ok:       true,
+		},
+		{
+			name:  "invalid",
+			value: "soon",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, ok := parseRetryAfter(tc.value, now)
+			if diff := cmp.Diff([]any{tc.expected, tc.ok}, []any{d, ok}); diff != "" {
+				t.Errorf("parseRetryAfter() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
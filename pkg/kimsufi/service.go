@@ -1,6 +1,7 @@
 package kimsufi
 
 import (
+	"context"
 	"fmt"
 	"maps"
 	"net/http"
@@ -22,20 +23,36 @@ import (
 type MultiService map[string]Service
 
 // Service is a wrapper around ovh.Client
-// with optional caching and logging.
+// with optional in-memory caching (not used by the CLI) and logging.
 type Service struct {
 	cache  *cache.Cache
-	client *ovh.Client
+	client Client
 	logger *Logger
+	stats  *cacheStats
+}
+
+// Credentials holds the OVH API credentials.
+// see https://help.ovhcloud.com/csm/en-gb-api-getting-started-ovhcloud-api?id=kb_article_view&sysparm_article=KB0042784
+type Credentials struct {
+	AppKey      string
+	AppSecret   string
+	ConsumerKey string
 }
 
 // NewMultiService creates a new MultiService
 // with a Service for each OVH endpoint.
 func NewMultiService(l *log.Logger, c *cache.Cache) (MultiService, error) {
+	return NewMultiServiceWithTransport(NewRetryTransport(DefaultRetryPolicy, nil, l), l, c)
+}
+
+// NewMultiServiceWithTransport creates a new MultiService
+// with a Service for each OVH endpoint, sending the HTTP requests with transport.
+// transport is optional, if nil http.DefaultTransport will be used and failed requests are not retried.
+func NewMultiServiceWithTransport(transport http.RoundTripper, l *log.Logger, c *cache.Cache) (MultiService, error) {
 	m := make(MultiService, 0)
 
 	for _, endpoint := range GetOVHEndpoints() {
-		s, err := NewService(endpoint, l, c)
+		s, err := NewServiceWithTransport(endpoint, transport, l, c)
 		if err != nil {
 			log.Errorf("failed to create OVH client for %s: %v", endpoint, err)
 			return nil, err
@@ -58,10 +75,22 @@ func (m MultiService) Endpoint(endpoint string) *Service {
 }
 
 // NewService creates a new Service for the given endpoint.
+// endpoint is either an OVH endpoint name (e.g. ovh-eu) or an API URL (e.g. http://127.0.0.1:8080 for a fake API).
+// Failed requests are retried with the DefaultRetryPolicy.
 // logger is optional, if nil a no-op logger will be used.
 // c is optional, if nil no caching will be used.
 func NewService(endpoint string, logger *log.Logger, c *cache.Cache) (*Service, error) {
+	return NewServiceWithTransport(endpoint, NewRetryTransport(DefaultRetryPolicy, nil, logger), logger, c)
+}
+
+// NewServiceWithTransport creates a new Service for the given endpoint,
+// sending the HTTP requests with transport (e.g. to record or replay them, or a RetryTransport).
+// transport is optional, if nil http.DefaultTransport will be used and failed requests are not retried.
+func NewServiceWithTransport(endpoint string, transport http.RoundTripper, logger *log.Logger, c *cache.Cache) (*Service, error) {
 	e, found := ovh.Endpoints[endpoint]
+	if !found && IsURLEndpoint(endpoint) {
+		e, found = strings.TrimSuffix(endpoint, "/"), true
+	}
 	if !found {
 		return nil, fmt.Errorf("invalid endpoint %s", endpoint)
 	}
@@ -71,17 +100,34 @@ func NewService(endpoint string, logger *log.Logger, c *cache.Cache) (*Service,
 		return nil, err
 	}
 
-	s := &Service{
-		cache:  c,
-		client: client,
-		logger: NewRequestLogger(logger),
+	if transport != nil {
+		client.Client.Transport = transport
 	}
 
+	s := NewServiceWithClient(client, logger, c)
 	client.Logger = s.logger
 
 	return s, nil
 }
 
+// NewServiceWithClient creates a new Service using the given OVH API client,
+// e.g. a fake client in tests.
+// logger is optional, if nil a no-op logger will be used.
+// c is optional, if nil no caching will be used.
+func NewServiceWithClient(client Client, logger *log.Logger, c *cache.Cache) *Service {
+	return &Service{
+		cache:  c,
+		client: client,
+		logger: NewRequestLogger(logger),
+		stats:  &cacheStats{},
+	}
+}
+
+// IsURLEndpoint returns true if the endpoint is an http or https URL instead of an OVH endpoint name.
+func IsURLEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://")
+}
+
 // GetOVHEndpoints returns a list of OVH endpoints.
 // It keeps only the ones starting with "ovh-".
 func GetOVHEndpoints() []string {
@@ -99,6 +145,11 @@ func GetOVHEndpoints() []string {
 // options is a map of additional query parameters.
 // see https://eu.api.ovh.com/console/?section=%2Fdedicated%2Fserver&branch=v1#get-/dedicated/server/datacenter/availabilities
 func (s *Service) GetAvailabilities(datacenters []string, planCode string, options map[string]string) (*kimsufiavailability.Availabilities, error) {
+	return s.GetAvailabilitiesWithContext(context.Background(), datacenters, planCode, options)
+}
+
+// GetAvailabilitiesWithContext is like GetAvailabilities but uses ctx for the API requests.
+func (s *Service) GetAvailabilitiesWithContext(ctx context.Context, datacenters []string, planCode string, options map[string]string) (*kimsufiavailability.Availabilities, error) {
 	path := "/dedicated/server/datacenter/availabilities"
 
 	queryArgs := make(map[string]string)
@@ -111,7 +162,7 @@ func (s *Service) GetAvailabilities(datacenters []string, planCode string, optio
 	maps.Copy(queryArgs, options)
 
 	var availabilities *kimsufiavailability.Availabilities
-	err := s.request(http.MethodGet, path, queryArgs, nil, &availabilities, false)
+	err := s.request(ctx, http.MethodGet, path, queryArgs, nil, &availabilities, false)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +174,11 @@ func (s *Service) GetAvailabilities(datacenters []string, planCode string, optio
 // ovhSubsidiary is the country code to filter on, given in a two-letter format.
 // see https://eu.api.ovh.com/console/?section=%2Forder&branch=v1#get-/order/catalog/public/eco
 func (s *Service) ListServers(ovhSubsidiary string) (*kimsuficatalog.Catalog, error) {
+	return s.ListServersWithContext(context.Background(), ovhSubsidiary)
+}
+
+// ListServersWithContext is like ListServers but uses ctx for the API requests.
+func (s *Service) ListServersWithContext(ctx context.Context, ovhSubsidiary string) (*kimsuficatalog.Catalog, error) {
 	path := "/order/catalog/public/eco"
 
 	queryArgs := map[string]string{
@@ -130,7 +186,7 @@ func (s *Service) ListServers(ovhSubsidiary string) (*kimsuficatalog.Catalog, er
 	}
 
 	var catalog *kimsuficatalog.Catalog
-	err := s.request(http.MethodGet, path, queryArgs, nil, &catalog, false)
+	err := s.request(ctx, http.MethodGet, path, queryArgs, nil, &catalog, false)
 	if err != nil {
 		return nil, err
 	}
@@ -140,9 +196,14 @@ func (s *Service) ListServers(ovhSubsidiary string) (*kimsuficatalog.Catalog, er
 
 // GetAuthDetails performs a test API request to check if the client is authenticated.
 func (s *Service) GetAuthDetails() error {
+	return s.GetAuthDetailsWithContext(context.Background())
+}
+
+// GetAuthDetailsWithContext is like GetAuthDetails but uses ctx for the API requests.
+func (s *Service) GetAuthDetailsWithContext(ctx context.Context) error {
 	path := "/auth/details"
 
-	err := s.client.Get(path, nil)
+	err := s.client.GetWithContext(ctx, path, nil)
 	if err != nil {
 		return err
 	}
@@ -151,10 +212,15 @@ func (s *Service) GetAuthDetails() error {
 }
 
 func (s *Service) GetCurrentCredential() (*kimsufiauthentication.CurrentCredentialResponse, error) {
+	return s.GetCurrentCredentialWithContext(context.Background())
+}
+
+// GetCurrentCredentialWithContext is like GetCurrentCredential but uses ctx for the API requests.
+func (s *Service) GetCurrentCredentialWithContext(ctx context.Context) (*kimsufiauthentication.CurrentCredentialResponse, error) {
 	path := "/auth/currentCredential"
 
 	var resp kimsufiauthentication.CurrentCredentialResponse
-	err := s.client.Get(path, &resp)
+	err := s.client.GetWithContext(ctx, path, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -163,8 +229,17 @@ func (s *Service) GetCurrentCredential() (*kimsufiauthentication.CurrentCredenti
 }
 
 // WithAuth returns a new authenticated Service with the given credentials.
+// The client is authenticated using its Authenticator implementation when available.
 func (s *Service) WithAuth(appKey, appSecret, consumerKey string) (*Service, error) {
-	authClient, err := ovh.NewClient(s.client.Endpoint(), appKey, appSecret, consumerKey)
+	var (
+		authClient Client
+		err        error
+	)
+	if a, ok := s.client.(Authenticator); ok {
+		authClient, err = a.WithAuth(appKey, appSecret, consumerKey)
+	} else {
+		authClient, err = newAuthClient(s.client, appKey, appSecret, consumerKey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -173,17 +248,34 @@ func (s *Service) WithAuth(appKey, appSecret, consumerKey string) (*Service, err
 		cache:  s.cache,
 		logger: s.logger,
 		client: authClient,
+		stats:  s.stats,
 	}
 
 	return newService, nil
 }
 
+// newAuthClient returns an ovh.Client for the client endpoint with the given credentials.
+// The HTTP client and logger of an ovh.Client are kept.
+func newAuthClient(client Client, appKey, appSecret, consumerKey string) (Client, error) {
+	authClient, err := ovh.NewClient(client.Endpoint(), appKey, appSecret, consumerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := client.(*ovh.Client); ok {
+		authClient.Client = c.Client
+		authClient.Logger = c.Logger
+	}
+
+	return authClient, nil
+}
+
 // request performs an API request.
-// this is a wrapper around ovh.Client.CallAPI, it allows for caching when set on the Service.
+// this is a wrapper around Client.CallAPIWithContext, it allows for caching of GET requests when set on the Service.
 // path and queryArgs are combined to form the request URL.
 // method, body, response, and needAuth are passed as is.
 // response must be a pointer.
-func (s *Service) request(method, path string, queryArgs map[string]string, body any, response any, needAuth bool) error {
+func (s *Service) request(ctx context.Context, method, path string, queryArgs map[string]string, body any, response any, needAuth bool) error {
 	// Ensure response is a pointer
 	rv := reflect.ValueOf(response)
 	if rv.Kind() != reflect.Pointer || rv.IsNil() {
@@ -200,27 +292,30 @@ func (s *Service) request(method, path string, queryArgs map[string]string, body
 	}
 	u.RawQuery = q.Encode()
 
+	if s.cache == nil || method != http.MethodGet {
+		return s.client.CallAPIWithContext(ctx, method, u.String(), body, response, needAuth)
+	}
+
 	cacheKey := fmt.Sprintf("%s%s", s.client.Endpoint(), u.String())
 
-	found := false
-	var cacheEntry any
-	if s.cache != nil {
-		cacheEntry, found = s.cache.Get(cacheKey)
-	}
-	if found {
+	if e, found := s.cache.Get(cacheKey); found {
 		s.logger.Tracef("cache hit: %s", cacheKey)
-		ce := reflect.ValueOf(cacheEntry)
-		rv.Elem().Set(ce.Elem())
-	} else {
-		s.logger.Tracef("cache miss: %s", cacheKey)
-		err = s.client.CallAPI(method, u.String(), body, response, needAuth)
-		if err != nil {
-			return err
-		}
-		if s.cache != nil {
-			s.cache.Set(cacheKey, response, cache.DefaultExpiration)
+		s.stats.hits.Add(1)
+		return e.(*cacheEntry).decode(response)
+	}
+
+	s.logger.Tracef("cache miss: %s", cacheKey)
+	s.stats.misses.Add(1)
+
+	e, err := s.fetch(ctx, u.String(), cacheKey, needAuth)
+	if err != nil {
+		// Cache the absence of availabilities, as it is requested repeatedly
+		if IsAvailabilityNotFoundError(err) {
+			s.cache.Set(cacheKey, &cacheEntry{err: err}, cache.DefaultExpiration)
 		}
+		return err
 	}
+	s.store(cacheKey, e)
 
-	return nil
+	return e.decode(response)
 }
@@ -1,8 +1,18 @@
 package kimsufi
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/ovh/go-ovh/ovh"
+	"github.com/patrickmn/go-cache"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -96,3 +106,221 @@ func TestServiceLogger(t *testing.T) {
 		t.Error("expected logger to be set")
 	}
 }
+
+func TestServiceWithContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Block until the client gives up
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client, err := ovh.NewClient(server.URL, "none", "none", "none")
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	s := NewServiceWithClient(client, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = s.GetAvailabilitiesWithContext(ctx, nil, "24ska01", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline exceeded error, got %v", err)
+	}
+
+	_, err = s.CreateCartWithContext(ctx, "FR", time.Now())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline exceeded error, got %v", err)
+	}
+}
+
+// fakeClient is a Client returning the responses by path, as JSON.
+type fakeClient struct {
+	responses map[string]string
+	calls     []string
+	auth      bool
+}
+
+func (f *fakeClient) Endpoint() string {
+	return "https://fake.example.com/1.0"
+}
+
+func (f *fakeClient) CallAPIWithContext(ctx context.Context, method, path string, reqBody, resType any, needAuth bool) error {
+	f.calls = append(f.calls, method+" "+path)
+
+	response, found := f.responses[path]
+	if !found {
+		return &ovh.APIError{Code: http.StatusNotFound, Message: "No availabilities found"}
+	}
+	if resType == nil {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(response), resType)
+}
+
+func (f *fakeClient) GetWithContext(ctx context.Context, url string, resType any) error {
+	return f.CallAPIWithContext(ctx, http.MethodGet, url, nil, resType, true)
+}
+
+func (f *fakeClient) GetUnAuthWithContext(ctx context.Context, url string, resType any) error {
+	return f.CallAPIWithContext(ctx, http.MethodGet, url, nil, resType, false)
+}
+
+func (f *fakeClient) PostWithContext(ctx context.Context, url string, reqBody, resType any) error {
+	return f.CallAPIWithContext(ctx, http.MethodPost, url, reqBody, resType, true)
+}
+
+func (f *fakeClient) PostUnAuthWithContext(ctx context.Context, url string, reqBody, resType any) error {
+	return f.CallAPIWithContext(ctx, http.MethodPost, url, reqBody, resType, false)
+}
+
+func (f *fakeClient) DeleteUnAuthWithContext(ctx context.Context, url string, resType any) error {
+	return f.CallAPIWithContext(ctx, http.MethodDelete, url, nil, resType, false)
+}
+
+func (f *fakeClient) WithAuth(appKey, appSecret, consumerKey string) (Client, error) {
+	return &fakeClient{responses: f.responses, auth: true}, nil
+}
+
+func TestNewServiceWithClient(t *testing.T) {
+	client := &fakeClient{
+		responses: map[string]string{
+			"/dedicated/server/datacenter/availabilities?planCode=24ska01": `[{"planCode":"24ska01","datacenters":[{"datacenter":"gra","availability":"1H-high"}]}]`,
+		},
+	}
+	s := NewServiceWithClient(client, nil, cache.New(time.Minute, time.Minute))
+
+	for range 2 {
+		availabilities, err := s.GetAvailabilities(nil, "24ska01", nil)
+		if err != nil {
+			t.Fatalf("GetAvailabilities failed: %v", err)
+		}
+
+		codes := (*availabilities)[0].GetAvailableDatacenters().Codes()
+		if diff := cmp.Diff([]string{"gra"}, codes); diff != "" {
+			t.Errorf("GetAvailableDatacenters() mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	// Second call is served from cache
+	expected := []string{"GET /dedicated/server/datacenter/availabilities?planCode=24ska01"}
+	if diff := cmp.Diff(expected, client.calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err := s.GetAvailabilities(nil, "24sk10", nil)
+	if !IsAvailabilityNotFoundError(err) {
+		t.Errorf("expected availability not found error, got %v", err)
+	}
+}
+
+func TestServiceWithAuthAuthenticator(t *testing.T) {
+	s := NewServiceWithClient(&fakeClient{}, nil, nil)
+
+	authService, err := s.WithAuth("key", "secret", "consumer")
+	if err != nil {
+		t.Fatalf("WithAuth failed: %v", err)
+	}
+
+	client, ok := authService.client.(*fakeClient)
+	if !ok || !client.auth {
+		t.Errorf("expected authenticated fake client, got %#v", authService.client)
+	}
+}
+
+func TestServiceCacheImmutable(t *testing.T) {
+	client := &fakeClient{
+		responses: map[string]string{
+			"/order/catalog/public/eco?ovhSubsidiary=FR": `{"plans":[{"planCode":"24ska01"},{"planCode":"24sk10"}]}`,
+		},
+	}
+	s := NewServiceWithClient(client, nil, cache.New(time.Minute, time.Minute))
+
+	catalog, err := s.ListServers("FR")
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+
+	// Mutate the first result, like list sorting the plans
+	catalog.Plans[0], catalog.Plans[1] = catalog.Plans[1], catalog.Plans[0]
+	catalog.Plans[0].PlanCode = "mutated"
+
+	catalog, err = s.ListServers("FR")
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+
+	planCodes := []string{catalog.Plans[0].PlanCode, catalog.Plans[1].PlanCode}
+	if diff := cmp.Diff([]string{"24ska01", "24sk10"}, planCodes); diff != "" {
+		t.Errorf("plan codes mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(CacheStats{Hits: 1, Misses: 1}, s.CacheStats()); diff != "" {
+		t.Errorf("CacheStats() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServiceCacheNegative(t *testing.T) {
+	client := &fakeClient{}
+	s := NewServiceWithClient(client, nil, cache.New(time.Minute, time.Minute))
+
+	for range 2 {
+		_, err := s.GetAvailabilities(nil, "24sk10", nil)
+		if !IsAvailabilityNotFoundError(err) {
+			t.Errorf("expected availability not found error, got %v", err)
+		}
+	}
+
+	// Second call is served from cache
+	if len(client.calls) != 1 {
+		t.Errorf("expected 1 call, got %v", client.calls)
+	}
+
+	if diff := cmp.Diff(CacheStats{Hits: 1, Misses: 1}, s.CacheStats()); diff != "" {
+		t.Errorf("CacheStats() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServiceCacheRevalidate(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("If-None-Match"))
+
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, `[{"planCode":"24ska01","datacenters":[{"datacenter":"gra","availability":"1H-high"}]}]`) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	s, err := NewService(server.URL, nil, cache.New(20*time.Millisecond, time.Minute))
+	if err != nil {
+		t.Fatalf("NewService failed: %v", err)
+	}
+
+	for range 2 {
+		availabilities, err := s.GetAvailabilities(nil, "24ska01", nil)
+		if err != nil {
+			t.Fatalf("GetAvailabilities failed: %v", err)
+		}
+
+		codes := (*availabilities)[0].GetAvailableDatacenters().Codes()
+		if diff := cmp.Diff([]string{"gra"}, codes); diff != "" {
+			t.Errorf("GetAvailableDatacenters() mismatch (-want +got):\n%s", diff)
+		}
+
+		// Let the response expire, its validators are kept for one more expiration period
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	if diff := cmp.Diff([]string{"", `"v1"`}, requests); diff != "" {
+		t.Errorf("If-None-Match headers mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(CacheStats{Misses: 2, Revalidated: 1}, s.CacheStats()); diff != "" {
+		t.Errorf("CacheStats() mismatch (-want +got):\n%s", diff)
+	}
+}
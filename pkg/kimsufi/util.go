@@ -3,6 +3,8 @@ package kimsufi
 import (
 	"fmt"
 	"strings"
+
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
 )
 
 // AddonGenericName returns the generic name of an addon.
@@ -17,6 +19,24 @@ func AddonGenericName(name string) string {
 	return name
 }
 
+// FindAddon returns the addon of the plan family matching the generic name, or an empty string when not found.
+// It is the reverse of AddonGenericName,
+// e.g. memory, ram-64g-ecc-2400 -> ram-64g-ecc-2400-24sk50
+func FindAddon(plan *kimsuficatalog.Plan, family, genericName string) string {
+	f := plan.GetAddon(family)
+	if f == nil {
+		return ""
+	}
+
+	for _, addon := range f.Addons {
+		if AddonGenericName(addon) == genericName {
+			return addon
+		}
+	}
+
+	return ""
+}
+
 // IntervalToDuration converts an interval and a unit to a duration string.
 // examples:
 // - 1  year   -> P1Y
@@ -1,6 +1,10 @@
 package kimsufi
 
-import "testing"
+import (
+	"testing"
+
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
 
 func TestIntervalToDuration(t *testing.T) {
 	testCases := []struct {
@@ -26,3 +30,32 @@ func TestIntervalToDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestFindAddon(t *testing.T) {
+	plan := &kimsuficatalog.Plan{
+		PlanCode: "24ska01",
+		AddonFamilies: []kimsuficatalog.PlanAddonFamily{
+			{
+				Name:   kimsuficatalog.AddonMemory,
+				Addons: []string{"ram-32g-noecc-2133-24ska01", "ram-64g-noecc-2133-24ska01"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		family      string
+		genericName string
+		expected    string
+	}{
+		{family: kimsuficatalog.AddonMemory, genericName: "ram-64g-noecc-2133", expected: "ram-64g-noecc-2133-24ska01"},
+		{family: kimsuficatalog.AddonMemory, genericName: "ram-128g-noecc-2133", expected: ""},
+		{family: kimsuficatalog.AddonStorage, genericName: "softraid-2x2000sa", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		actual := FindAddon(plan, tc.family, tc.genericName)
+		if actual != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// colorAvailable is the embed color, green.
+	colorAvailable = 0x2ecc71
+	// fieldEmpty is used for unknown field values, Discord rejects empty values.
+	fieldEmpty = "-"
+)
+
+// Notifier sends events to a Discord channel using an incoming webhook.
+// see https://discord.com/developers/docs/resources/webhook#execute-webhook
+type Notifier struct {
+	WebhookURL string
+	Username   string
+	Client     *http.Client
+}
+
+// Payload represents a Discord webhook message.
+type Payload struct {
+	Username string  `json:"username,omitempty"`
+	Embeds   []Embed `json:"embeds"`
+}
+
+// Embed represents a Discord message embed.
+// see https://discord.com/developers/docs/resources/message#embed-object
+type Embed struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Color       int          `json:"color"`
+	Fields      []EmbedField `json:"fields"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+}
+
+// EmbedField represents a Discord embed field.
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// New creates a new Notifier posting to the given webhook URL.
+func New(webhookURL string) *Notifier {
+	return &Notifier{
+		WebhookURL: webhookURL,
+		Username:   "kimsufi-notifier",
+		Client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send sends the event as a message embed.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	return notifier.PostJSON(ctx, n.Client, n.WebhookURL, n.NewPayload(e))
+}
+
+// NewPayload returns the webhook message for the event.
+func (n *Notifier) NewPayload(e notifier.Event) Payload {
+	var datacenters []string
+	for _, d := range e.Datacenters {
+		datacenters = append(datacenters, "- "+d)
+	}
+
+	embed := Embed{
+		Title:       e.InvoiceName,
+		Description: fmt.Sprintf("`%s` is available", e.PlanCode),
+		Color:       colorAvailable,
+		Fields: []EmbedField{
+			{Name: "CPU", Value: valueOrEmpty(e.Specs.CPU), Inline: true},
+			{Name: "Memory", Value: valueOrEmpty(e.Specs.Memory), Inline: true},
+			{Name: "Storage", Value: valueOrEmpty(strings.Join(e.Specs.Storage, "\n")), Inline: true},
+			{Name: "Price", Value: valueOrEmpty(e.FormatPrice()), Inline: true},
+			{Name: "Datacenters", Value: valueOrEmpty(strings.Join(datacenters, "\n")), Inline: false},
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	return Payload{
+		Username: n.Username,
+		Embeds:   []Embed{embed},
+	}
+}
+
+// valueOrEmpty returns v, or fieldEmpty when v is empty.
+func valueOrEmpty(v string) string {
+	if v == "" {
+		return fieldEmpty
+	}
+
+	return v
+}
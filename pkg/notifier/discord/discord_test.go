@@ -0,0 +1,65 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+func TestSend(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&received)
+		if err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	e := notifier.Event{
+		PlanCode:    "24ska01",
+		InvoiceName: "KS-A | Intel i7-6700k",
+		Datacenters: []string{"Gravelines (France)", "Roubaix (France)"},
+		Price:       4.99,
+		Currency:    "EUR",
+		Specs: notifier.Specs{
+			CPU:     "Intel i7-6700k 4.00 GHz",
+			Storage: []string{"2 x 2000 Go HDD"},
+		},
+	}
+
+	err := New(server.URL).Send(context.Background(), e)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	want := Payload{
+		Username: "kimsufi-notifier",
+		Embeds: []Embed{
+			{
+				Title:       "KS-A | Intel i7-6700k",
+				Description: "`24ska01` is available",
+				Color:       colorAvailable,
+				Fields: []EmbedField{
+					{Name: "CPU", Value: "Intel i7-6700k 4.00 GHz", Inline: true},
+					{Name: "Memory", Value: "-", Inline: true},
+					{Name: "Storage", Value: "2 x 2000 Go HDD", Inline: true},
+					{Name: "Price", Value: "4.99 EUR", Inline: true},
+					{Name: "Datacenters", Value: "- Gravelines (France)\n- Roubaix (France)"},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, received, cmpopts.IgnoreFields(Embed{}, "Timestamp")); diff != "" {
+		t.Errorf("payload mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,246 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// TLSModeNone sends emails unencrypted.
+	TLSModeNone = "none"
+	// TLSModeSTARTTLS upgrades the connection to TLS using the STARTTLS command.
+	TLSModeSTARTTLS = "starttls"
+	// TLSModeTLS connects using implicit TLS, usually on port 465.
+	TLSModeTLS = "tls"
+
+	// AuthModeNone does not authenticate.
+	AuthModeNone = "none"
+	// AuthModePlain authenticates using the PLAIN mechanism.
+	AuthModePlain = "plain"
+	// AuthModeLogin authenticates using the LOGIN mechanism.
+	AuthModeLogin = "login"
+
+	PortDefault    = 587
+	TimeoutDefault = 30 * time.Second
+)
+
+var (
+	// TLSModes is the list of supported TLS modes.
+	TLSModes = []string{TLSModeSTARTTLS, TLSModeTLS, TLSModeNone}
+	// AuthModes is the list of supported authentication modes.
+	AuthModes = []string{AuthModePlain, AuthModeLogin, AuthModeNone}
+)
+
+// Notifier sends events by email over SMTP.
+// All the events of a batch are grouped into a single email.
+type Notifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// Auth is one of AuthModes.
+	Auth string
+	// TLS is one of TLSModes.
+	TLS string
+	// TLSConfig is optional, a default configuration using Host as server name is used when nil.
+	TLSConfig *tls.Config
+	Timeout   time.Duration
+
+	From string
+	To   []string
+}
+
+// New creates a new Notifier sending emails from from to to, using STARTTLS and PLAIN authentication.
+func New(host string, from string, to []string) *Notifier {
+	return &Notifier{
+		Host:    host,
+		Port:    PortDefault,
+		Auth:    AuthModePlain,
+		TLS:     TLSModeSTARTTLS,
+		Timeout: TimeoutDefault,
+		From:    from,
+		To:      to,
+	}
+}
+
+// Send sends a single event by email.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	return n.SendBatch(ctx, []notifier.Event{e})
+}
+
+// SendBatch sends all the events in a single email.
+func (n *Notifier) SendBatch(ctx context.Context, events []notifier.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if len(n.To) == 0 {
+		return errors.New("no recipient")
+	}
+
+	msg, err := NewMessage(n.From, n.To, events)
+	if err != nil {
+		return err
+	}
+
+	c, err := n.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close() // nolint:errcheck
+
+	err = n.authenticate(c)
+	if err != nil {
+		return err
+	}
+
+	err = c.Mail(n.From)
+	if err != nil {
+		return fmt.Errorf("MAIL FROM failed: %w", err)
+	}
+
+	for _, to := range n.To {
+		err = c.Rcpt(to)
+		if err != nil {
+			return fmt.Errorf("RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("DATA failed: %w", err)
+	}
+
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("DATA failed: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// dial connects to the SMTP server, and upgrades the connection to TLS according to the TLS mode.
+func (n *Notifier) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+
+	tlsConfig := n.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: n.Host, MinVersion: tls.VersionTLS12}
+	}
+
+	d := &net.Dialer{Timeout: n.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound the whole SMTP session, not only the connection.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) // nolint:errcheck
+	} else if n.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(n.Timeout)) // nolint:errcheck
+	}
+
+	switch n.TLS {
+	case TLSModeTLS:
+		conn = tls.Client(conn, tlsConfig)
+	case TLSModeSTARTTLS, TLSModeNone:
+	default:
+		conn.Close() // nolint:errcheck
+		return nil, fmt.Errorf("unknown TLS mode %q", n.TLS)
+	}
+
+	c, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		conn.Close() // nolint:errcheck
+		return nil, err
+	}
+
+	if n.TLS == TLSModeSTARTTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			c.Close() // nolint:errcheck
+			return nil, errors.New("server does not support STARTTLS")
+		}
+
+		err = c.StartTLS(tlsConfig)
+		if err != nil {
+			c.Close() // nolint:errcheck
+			return nil, fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// authenticate authenticates against the SMTP server according to the authentication mode.
+func (n *Notifier) authenticate(c *smtp.Client) error {
+	var auth smtp.Auth
+
+	switch n.Auth {
+	case AuthModeNone, "":
+		return nil
+	case AuthModePlain:
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	case AuthModeLogin:
+		auth = &loginAuth{username: n.Username, password: n.Password}
+	default:
+		return fmt.Errorf("unknown authentication mode %q", n.Auth)
+	}
+
+	err := c.Auth(auth)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return nil
+}
+
+// loginAuth implements the LOGIN authentication mechanism.
+// It is not part of net/smtp, but is still widely used (e.g. Office 365).
+type loginAuth struct {
+	username string
+	password string
+}
+
+// Start begins the LOGIN authentication, it refuses to send credentials over an unencrypted connection.
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+// Next answers the server username and password challenges.
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch string(fromServer) {
+	case "Username:", "User Name\x00":
+		return []byte(a.username), nil
+	case "Password:", "Password\x00":
+		return []byte(a.password), nil
+	}
+
+	return nil, fmt.Errorf("unexpected server challenge %q", fromServer)
+}
+
+// isLocalhost returns true if name is a loopback host.
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
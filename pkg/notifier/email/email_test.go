@@ -0,0 +1,252 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+// smtpServer is a minimal in-process SMTP server recording the received emails.
+type smtpServer struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	auth       []string
+	from       string
+	recipients []string
+	data       string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	s := &smtpServer{listener: l}
+	s.wg.Add(1)
+	go s.serve()
+
+	t.Cleanup(func() {
+		l.Close() // nolint:errcheck
+		s.wg.Wait()
+	})
+
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close() // nolint:errcheck
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n")) // nolint:errcheck
+	}
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err == nil
+	}
+	decode := func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second)) // nolint:errcheck
+
+	reply("220 localhost ESMTP")
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.mu.Lock()
+		switch {
+		case cmd == "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN LOGIN")
+		case strings.HasPrefix(line, "AUTH PLAIN "):
+			s.auth = append(s.auth, "PLAIN", decode(strings.TrimPrefix(line, "AUTH PLAIN ")))
+			reply("235 Authentication successful")
+		case line == "AUTH LOGIN":
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			username, _ := readLine()
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			password, _ := readLine()
+			s.auth = append(s.auth, "LOGIN", decode(username)+":"+decode(password))
+			reply("235 Authentication successful")
+		case cmd == "MAIL":
+			s.from = line
+			reply("250 OK")
+		case cmd == "RCPT":
+			s.recipients = append(s.recipients, line)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data []string
+			for {
+				l, ok := readLine()
+				if !ok || l == "." {
+					break
+				}
+				data = append(data, l)
+			}
+			s.data = strings.Join(data, "\n")
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			s.mu.Unlock()
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestSendBatch(t *testing.T) {
+	events := []notifier.Event{
+		{
+			PlanCode:    "24ska01",
+			InvoiceName: "KS-A | Intel i7-6700k",
+			Datacenters: []string{"Gravelines (France)"},
+			Price:       4.99,
+			Currency:    "EUR",
+		},
+		{
+			PlanCode:    "24sk10",
+			InvoiceName: "KS-1 | Intel Xeon-D 1520",
+			Datacenters: []string{"Beauharnois (Canada)"},
+			Price:       16.99,
+			Currency:    "EUR",
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		auth         string
+		expectedAuth []string
+	}{
+		{
+			name:         "plain auth",
+			auth:         AuthModePlain,
+			expectedAuth: []string{"PLAIN", "\x00user\x00secret"},
+		},
+		{
+			name:         "login auth",
+			auth:         AuthModeLogin,
+			expectedAuth: []string{"LOGIN", "user:secret"},
+		},
+		{
+			name:         "no auth",
+			auth:         AuthModeNone,
+			expectedAuth: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newSMTPServer(t)
+
+			n := New("127.0.0.1", "notifier@example.com", []string{"a@example.com", "b@example.com"})
+			n.Port = s.port()
+			n.TLS = TLSModeNone
+			n.Auth = tc.auth
+			n.Username = "user"
+			n.Password = "secret"
+
+			err := n.SendBatch(context.Background(), events)
+			if err != nil {
+				t.Fatalf("SendBatch failed: %v", err)
+			}
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if strings.Join(s.auth, "|") != strings.Join(tc.expectedAuth, "|") {
+				t.Errorf("expected auth %q, got %q", tc.expectedAuth, s.auth)
+			}
+			if s.from != "MAIL FROM:<notifier@example.com>" {
+				t.Errorf("unexpected sender %q", s.from)
+			}
+			if len(s.recipients) != 2 {
+				t.Errorf("expected 2 recipients, got %v", s.recipients)
+			}
+
+			for _, expected := range []string{
+				"Subject: 2 servers are available",
+				"Content-Type: text/plain; charset=utf-8",
+				"Content-Type: text/html; charset=utf-8",
+				"KS-A | Intel i7-6700k (24ska01)",
+				"Price: 16.99 EUR",
+				"<td>Beauharnois (Canada)</td>",
+			} {
+				if !strings.Contains(s.data, expected) {
+					t.Errorf("expected message to contain %q, got:\n%s", expected, s.data)
+				}
+			}
+		})
+	}
+}
+
+func TestSendSTARTTLSNotSupported(t *testing.T) {
+	s := newSMTPServer(t)
+
+	n := New("127.0.0.1", "notifier@example.com", []string{"a@example.com"})
+	n.Port = s.port()
+
+	err := n.Send(context.Background(), notifier.Event{PlanCode: "24ska01"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("expected STARTTLS error, got %v", err)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	testCases := []struct {
+		events   int
+		expected string
+	}{
+		{events: 1, expected: "KS-A is available"},
+		{events: 3, expected: "3 servers are available"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(tc.events), func(t *testing.T) {
+			events := make([]notifier.Event, tc.events)
+			events[0].InvoiceName = "KS-A"
+
+			actual := Subject(events)
+			if actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+// textTemplate renders the plain text body.
+var textTemplate = template.Must(template.New("text").Funcs(template.FuncMap{"join": strings.Join}).Parse(
+	`{{ range . }}{{ .InvoiceName }} ({{ .PlanCode }})
+{{ with .FormatPrice }}  Price: {{ . }}
+{{ end }}{{ with .Specs.CPU }}  CPU: {{ . }}
+{{ end }}{{ with .Specs.Memory }}  Memory: {{ . }}
+{{ end }}{{ with .Specs.Storage }}  Storage: {{ join . ", " }}
+{{ end }}  Datacenters: {{ join .Datacenters ", " }}
+
+{{ end }}`))
+
+// htmlTemplate renders the HTML body.
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{"join": strings.Join}).Parse(
+	`<!DOCTYPE html>
+<html>
+<body>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Server</th><th>Plan code</th><th>Price</th><th>CPU</th><th>Memory</th><th>Storage</th><th>Datacenters</th></tr>
+{{ range . }}<tr><td>{{ .InvoiceName }}</td><td>{{ .PlanCode }}</td><td>{{ .FormatPrice }}</td><td>{{ .Specs.CPU }}</td><td>{{ .Specs.Memory }}</td><td>{{ join .Specs.Storage ", " }}</td><td>{{ join .Datacenters ", " }}</td></tr>
+{{ end }}</table>
+</body>
+</html>
+`))
+
+// Subject returns the email subject for the events.
+func Subject(events []notifier.Event) string {
+	if len(events) == 1 {
+		return fmt.Sprintf("%s is available", events[0].InvoiceName)
+	}
+
+	return fmt.Sprintf("%d servers are available", len(events))
+}
+
+// NewMessage returns the email message for the events,
+// with both a plain text and an HTML body built from the same events.
+func NewMessage(from string, to []string, events []notifier.Event) ([]byte, error) {
+	var text, html bytes.Buffer
+
+	err := textTemplate.Execute(&text, events)
+	if err != nil {
+		return nil, err
+	}
+
+	err = htmlTemplate.Execute(&html, events)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	mw := multipart.NewWriter(&msg)
+
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", Subject(events)),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	msg.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		_, err = qp.Write(part.body)
+		if err != nil {
+			return nil, err
+		}
+
+		err = qp.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return msg.Bytes(), nil
+}
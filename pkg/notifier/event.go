@@ -0,0 +1,207 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
+
+// OrderURLBase is the OVH Eco website URL.
+const OrderURLBase = "https://eco.ovhcloud.com"
+
+// orderURLLanguages maps OVH subsidiaries to the OVH Eco website language.
+var orderURLLanguages = map[string]string{
+	"ASIA": "en-asia",
+	"AU":   "en-au",
+	"CA":   "en-ca",
+	"CZ":   "cs",
+	"DE":   "de",
+	"ES":   "es-es",
+	"FI":   "fi",
+	"FR":   "fr",
+	"GB":   "en-gb",
+	"IE":   "en-ie",
+	"IN":   "en-in",
+	"IT":   "it",
+	"LT":   "lt",
+	"MA":   "fr-ma",
+	"NL":   "nl",
+	"PL":   "pl",
+	"PT":   "pt",
+	"QC":   "fr-ca",
+	"SG":   "en-sg",
+	"SN":   "fr-sn",
+	"TN":   "fr-tn",
+	"US":   "en",
+	"WE":   "en",
+	"WS":   "es",
+}
+
+// Event represents a server availability to notify about.
+type Event struct {
+	PlanCode    string `json:"planCode"`
+	InvoiceName string `json:"invoiceName"`
+	Memory      string `json:"memory,omitempty"`
+	Storage     string `json:"storage,omitempty"`
+	// Datacenters holds the datacenters full names, or codes when the name is unknown.
+	Datacenters     []string `json:"datacenters"`
+	DatacenterCodes []string `json:"datacenterCodes"`
+	Price           float64  `json:"price"`
+	Currency        string   `json:"currency"`
+	// Subsidiary is the OVH subsidiary of the catalog, e.g. FR.
+	Subsidiary string `json:"subsidiary"`
+
+	// Specs holds the human readable hardware specifications, when known.
+	Specs Specs `json:"specs"`
+}
+
+// Specs represents human readable hardware specifications.
+type Specs struct {
+	// CPU e.g. Intel i7-6700k 4.00 GHz
+	CPU string `json:"cpu,omitempty"`
+	// Memory e.g. 32 Go DDR4
+	Memory string `json:"memory,omitempty"`
+	// Storage e.g. [2 x 2000 Go HDD]
+	Storage []string `json:"storage,omitempty"`
+}
+
+// NewEvent creates a new Event for the given plan code, memory, storage and datacenters.
+// catalog is optional, when set it is used to fill in the invoice name, price, currency, subsidiary and specs.
+// memory and storage are optional, the plan default addons are used for specs when empty.
+func NewEvent(catalog *kimsuficatalog.Catalog, planCode, memory, storage string, datacenters kimsufiavailability.Datacenters) Event {
+	e := Event{
+		PlanCode:        planCode,
+		InvoiceName:     planCode,
+		Memory:          memory,
+		Storage:         storage,
+		Datacenters:     datacenters.ToFullNamesOrCodes(),
+		DatacenterCodes: datacenters.Codes(),
+	}
+
+	if catalog == nil {
+		return e
+	}
+
+	e.Currency = catalog.Locale.CurrencyCode
+	e.Subsidiary = catalog.Locale.Subsidiary
+
+	plan := catalog.GetPlan(planCode)
+	if plan != nil {
+		e.InvoiceName = plan.InvoiceName
+		e.Price = plan.GetFirstPrice().GetPrice()
+		e.Specs = newSpecs(catalog, plan, memory, storage)
+	}
+
+	return e
+}
+
+// FormatPrice returns the human readable price of the event, e.g. 4.99 EUR.
+// It returns an empty string when the price is unknown.
+func (e Event) FormatPrice() string {
+	if e.Currency == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%.2f %s", e.Price, e.Currency)
+}
+
+// OrderURL returns the URL of the OVH Eco express order page for the event subsidiary,
+// with the plan and, when known, its memory and storage options in the cart.
+// e.g. https://eco.ovhcloud.com/fr/order/express/#/express/review?products=~(~(planCode~'24ska01~productId~'eco~pricingMode~'default~quantity~1))
+func (e Event) OrderURL() string {
+	language, ok := orderURLLanguages[strings.ToUpper(e.Subsidiary)]
+	if !ok {
+		language = "en"
+	}
+
+	// The products are encoded with JSURL, as expected by the order page.
+	product := fmt.Sprintf("planCode~'%s~productId~'eco~pricingMode~'default~quantity~1", e.PlanCode)
+
+	var options []string
+	for _, addon := range []string{e.Memory, e.Storage} {
+		if addon != "" {
+			// Addon plan codes are suffixed with the plan code, see kimsufi.AddonGenericName
+			options = append(options, fmt.Sprintf("(planCode~'%s-%s~pricingMode~'default~quantity~1)", addon, e.PlanCode))
+		}
+	}
+	if len(options) > 0 {
+		product += "~option~(~" + strings.Join(options, "~") + ")"
+	}
+
+	return fmt.Sprintf("%s/%s/order/express/#/express/review?products=~(~(%s))", OrderURLBase, language, product)
+}
+
+// FormatText returns a plain text description of the event,
+// the first line being a title.
+func (e Event) FormatText() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s is available", e.InvoiceName)
+	fmt.Fprintf(&b, "\nPlan code: %s", e.PlanCode)
+	if e.Specs.CPU != "" {
+		fmt.Fprintf(&b, "\nCPU: %s", e.Specs.CPU)
+	}
+	if e.Specs.Memory != "" {
+		fmt.Fprintf(&b, "\nMemory: %s", e.Specs.Memory)
+	}
+	if len(e.Specs.Storage) > 0 {
+		fmt.Fprintf(&b, "\nStorage: %s", strings.Join(e.Specs.Storage, ", "))
+	}
+	if price := e.FormatPrice(); price != "" {
+		fmt.Fprintf(&b, "\nPrice: %s", price)
+	}
+	fmt.Fprintf(&b, "\nDatacenters: %s", strings.Join(e.Datacenters, ", "))
+
+	return b.String()
+}
+
+// newSpecs returns the hardware specifications of the plan with the given memory and storage.
+// Memory and storage specifications are taken from their addon product,
+// or from the plan product when not found.
+func newSpecs(catalog *kimsuficatalog.Catalog, plan *kimsuficatalog.Plan, memory, storage string) Specs {
+	var (
+		s         Specs
+		technical kimsuficatalog.ProductBlobsTechnical
+	)
+
+	product := catalog.GetProduct(plan.Product)
+	if product != nil {
+		technical = product.Blobs.Technical
+	}
+
+	memoryProduct := addonProduct(catalog, plan, kimsuficatalog.AddonMemory, memory)
+	if memoryProduct != nil {
+		technical.Memory = memoryProduct.Blobs.Technical.Memory
+	}
+
+	storageProduct := addonProduct(catalog, plan, kimsuficatalog.AddonStorage, storage)
+	if storageProduct != nil {
+		technical.Storage = storageProduct.Blobs.Technical.Storage
+	}
+
+	if technical.Server.CPU.Brand != "" {
+		s.CPU = technical.Server.CPU.Format()
+	}
+	if technical.Memory.Size > 0 {
+		s.Memory = technical.Memory.Format()
+	}
+	s.Storage = technical.Storage.Format()
+
+	return s
+}
+
+// addonProduct returns the product of the given addon, or of the default addon of the family when empty.
+func addonProduct(catalog *kimsuficatalog.Catalog, plan *kimsuficatalog.Plan, family, addon string) *kimsuficatalog.Product {
+	if addon == "" {
+		f := plan.GetAddon(family)
+		if f == nil || f.Default == "" {
+			return nil
+		}
+		addon = kimsufi.AddonGenericName(f.Default)
+	}
+
+	return catalog.GetProduct(addon)
+}
@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+	kimsuficatalog "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/catalog"
+)
+
+func TestNewEvent(t *testing.T) {
+	catalog := &kimsuficatalog.Catalog{
+		Locale: kimsuficatalog.Locale{
+			CurrencyCode: "EUR",
+			Subsidiary:   "FR",
+		},
+		Plans: []kimsuficatalog.Plan{
+			{
+				PlanCode:    "24ska01",
+				InvoiceName: "KS-A | Intel i7-6700k",
+				Product:     "24ska01",
+				Pricings: []kimsuficatalog.PlanPricing{
+					{Interval: 1, Price: 499000000},
+				},
+				AddonFamilies: []kimsuficatalog.PlanAddonFamily{
+					{
+						Name:    kimsuficatalog.AddonStorage,
+						Default: "softraid-2x2000sa-24ska01",
+					},
+				},
+			},
+		},
+		Products: []kimsuficatalog.Product{
+			{
+				Name: "24ska01",
+				Blobs: kimsuficatalog.ProductBlobs{
+					Technical: kimsuficatalog.ProductBlobsTechnical{
+						Server: kimsuficatalog.ProductBlobsTechnicalServer{
+							CPU: kimsuficatalog.ProductBlobsTechnicalCPU{Brand: "Intel", Model: "i7-6700k", Frequency: 4},
+						},
+					},
+				},
+			},
+			{
+				Name: "ram-32g-noecc-2133",
+				Blobs: kimsuficatalog.ProductBlobs{
+					Technical: kimsuficatalog.ProductBlobsTechnical{
+						Memory: kimsuficatalog.ProductBlobsTechnicalMemory{Size: 32, RAMType: "DDR4"},
+					},
+				},
+			},
+			{
+				Name: "softraid-2x2000sa",
+				Blobs: kimsuficatalog.ProductBlobs{
+					Technical: kimsuficatalog.ProductBlobsTechnical{
+						Storage: kimsuficatalog.ProductBlobsTechnicalStorage{
+							Disks: []kimsuficatalog.ProductBlobsTechnicalStorageDisk{
+								{Number: 2, Capacity: 2000, Technology: "HDD"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	datacenters := kimsufiavailability.Datacenters{
+		{Datacenter: "gra", Availability: "1H-low"},
+		{Datacenter: "xyz", Availability: "72H"},
+	}
+
+	testCases := []struct {
+		name    string
+		catalog *kimsuficatalog.Catalog
+		storage string
+		want    Event
+	}{
+		{
+			name:    "without catalog",
+			catalog: nil,
+			storage: "softraid-2x2000sa",
+			want: Event{
+				PlanCode:        "24ska01",
+				InvoiceName:     "24ska01",
+				Memory:          "ram-32g-noecc-2133",
+				Storage:         "softraid-2x2000sa",
+				Datacenters:     []string{"Gravelines (France)", "xyz"},
+				DatacenterCodes: []string{"gra", "xyz"},
+			},
+		},
+		{
+			name:    "with catalog and default storage",
+			catalog: catalog,
+			storage: "",
+			want: Event{
+				PlanCode:        "24ska01",
+				InvoiceName:     "KS-A | Intel i7-6700k",
+				Memory:          "ram-32g-noecc-2133",
+				Datacenters:     []string{"Gravelines (France)", "xyz"},
+				DatacenterCodes: []string{"gra", "xyz"},
+				Price:           4.99,
+				Currency:        "EUR",
+				Subsidiary:      "FR",
+				Specs: Specs{
+					CPU:     "Intel i7-6700k 4.00 GHz",
+					Memory:  "32 Go DDR4",
+					Storage: []string{"2 x 2000 Go HDD"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewEvent(tc.catalog, "24ska01", "ram-32g-noecc-2133", tc.storage, datacenters)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("NewEvent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOrderURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name:     "plan",
+			event:    Event{PlanCode: "24ska01", Subsidiary: "FR"},
+			expected: "https://eco.ovhcloud.com/fr/order/express/#/express/review?products=~(~(planCode~'24ska01~productId~'eco~pricingMode~'default~quantity~1))",
+		},
+		{
+			name:     "configuration",
+			event:    Event{PlanCode: "24ska01", Memory: "ram-32g-noecc-2133", Storage: "softraid-2x2000sa", Subsidiary: "gb"},
+			expected: "https://eco.ovhcloud.com/en-gb/order/express/#/express/review?products=~(~(planCode~'24ska01~productId~'eco~pricingMode~'default~quantity~1~option~(~(planCode~'ram-32g-noecc-2133-24ska01~pricingMode~'default~quantity~1)~(planCode~'softraid-2x2000sa-24ska01~pricingMode~'default~quantity~1))))",
+		},
+		{
+			name:     "unknown subsidiary",
+			event:    Event{PlanCode: "24sk10", Storage: "softraid-2x450nvme"},
+			expected: "https://eco.ovhcloud.com/en/order/express/#/express/review?products=~(~(planCode~'24sk10~productId~'eco~pricingMode~'default~quantity~1~option~(~(planCode~'softraid-2x450nvme-24sk10~pricingMode~'default~quantity~1))))",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.event.OrderURL()
+			if actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
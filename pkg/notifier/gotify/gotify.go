@@ -0,0 +1,99 @@
+package gotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// PriorityDefault is the Gotify default priority.
+	PriorityDefault = 5
+
+	tokenHeader = "X-Gotify-Key"
+)
+
+// Priorities maps the priority names to their Gotify value.
+// Gotify priorities range from 0 to 10, the Android app only
+// shows a notification from 1, and plays a sound from 4 and above.
+// see https://github.com/gotify/android#message-priorities
+var Priorities = map[string]int{
+	"min":     0,
+	"low":     2,
+	"default": 5,
+	"high":    8,
+	"urgent":  10,
+}
+
+// Notifier sends events as messages to a Gotify server.
+// see https://gotify.net/api-docs#/message/createMessage
+type Notifier struct {
+	// BaseURL is the Gotify server URL, there is no public server.
+	BaseURL string
+	// Token is the application token.
+	Token    string
+	Priority int
+	Client   *http.Client
+}
+
+// Payload represents a Gotify message.
+type Payload struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// New creates a new Notifier sending messages to the Gotify server using the application token.
+func New(baseURL, token string) *Notifier {
+	return &Notifier{
+		BaseURL:  baseURL,
+		Token:    token,
+		Priority: PriorityDefault,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send sends the event as a message, clicking the notification opens the OVH order page.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	u := strings.TrimSuffix(n.BaseURL, "/") + "/message"
+	headers := map[string]string{tokenHeader: n.Token}
+
+	return notifier.PostJSONWithHeaders(ctx, n.Client, u, headers, n.NewPayload(e))
+}
+
+// NewPayload returns the message for the event.
+func (n *Notifier) NewPayload(e notifier.Event) Payload {
+	title, message, _ := strings.Cut(e.FormatText(), "\n")
+
+	return Payload{
+		Title:    title,
+		Message:  message,
+		Priority: n.Priority,
+		// see https://gotify.net/docs/msgextras#clientnotification
+		Extras: map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]string{"url": e.OrderURL()},
+			},
+		},
+	}
+}
+
+// ParsePriority returns the priority value from its name or number, e.g. high or 8.
+func ParsePriority(s string) (int, error) {
+	if p, ok := Priorities[strings.ToLower(s)]; ok {
+		return p, nil
+	}
+
+	p, err := strconv.Atoi(s)
+	if err != nil || p < Priorities["min"] || p > Priorities["urgent"] {
+		return 0, fmt.Errorf("invalid gotify priority %q, allowed values: min, low, default, high, urgent or 0 to 10", s)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,95 @@
+package gotify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+func TestSend(t *testing.T) {
+	var (
+		got   map[string]any
+		token string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get(tokenHeader)
+		if r.URL.Path != "/gotify/message" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&got)
+		if err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	n := New(server.URL+"/gotify/", "app-token")
+	n.Priority = Priorities["urgent"]
+
+	e := notifier.Event{
+		PlanCode:    "24ska01",
+		InvoiceName: "KS-A | Intel i7-6700k",
+		Datacenters: []string{"Beauharnois (Canada)"},
+		Subsidiary:  "CA",
+	}
+
+	err := n.Send(context.Background(), e)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	want := map[string]any{
+		"title":    "KS-A | Intel i7-6700k is available",
+		"message":  "Plan code: 24ska01\nDatacenters: Beauharnois (Canada)",
+		"priority": float64(10),
+		"extras": map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]any{"url": "https://eco.ovhcloud.com/en-ca/order/express/#/express/review?products=~(~(planCode~'24ska01~productId~'eco~pricingMode~'default~quantity~1))"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("payload mismatch (-want +got):\n%s", diff)
+	}
+
+	if token != "app-token" {
+		t.Errorf("unexpected token %q", token)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int
+		err      bool
+	}{
+		{input: "min", expected: 0},
+		{input: "low", expected: 2},
+		{input: "default", expected: 5},
+		{input: "HIGH", expected: 8},
+		{input: "urgent", expected: 10},
+		{input: "7", expected: 7},
+		{input: "11", err: true},
+		{input: "max", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParsePriority(tc.input)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package hook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// TimeoutDefault is the maximum duration of a command run.
+	TimeoutDefault = 30 * time.Second
+
+	// EnvVarPrefix is the prefix of the environment variables passed to the command.
+	EnvVarPrefix = "KIMSUFI_"
+)
+
+// Notifier runs a command for each event.
+// The event is passed as JSON on the command stdin,
+// and as KIMSUFI_* environment variables.
+type Notifier struct {
+	// Command is run using the shell, e.g. notify-send "$KIMSUFI_PLAN_CODE is available".
+	Command string
+	Timeout time.Duration
+	// Logger receives the command stderr output, defaults to the logrus standard logger.
+	Logger *log.Logger
+}
+
+// New creates a new Notifier running the given command.
+func New(command string) *Notifier {
+	return &Notifier{
+		Command: command,
+		Timeout: TimeoutDefault,
+		Logger:  log.StandardLogger(),
+	}
+}
+
+// Send runs the command for the event.
+// The command is killed when it runs for longer than Timeout,
+// its stderr output is logged, and its stdout output is discarded.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	input, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.Command)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), Env(e)...)
+	// Do not wait for orphaned sub-processes holding stderr open after the command is killed.
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	n.logStderr(e, &stderr)
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("command timed out after %s", n.Timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	return nil
+}
+
+// logStderr logs each line the command wrote to stderr.
+func (n *Notifier) logStderr(e notifier.Event, stderr *bytes.Buffer) {
+	logger := n.Logger
+	if logger == nil {
+		logger = log.StandardLogger()
+	}
+
+	s := bufio.NewScanner(stderr)
+	for s.Scan() {
+		logger.WithField("planCode", e.PlanCode).Warnf("on-available: %s", s.Text())
+	}
+}
+
+// Env returns the environment variables describing the event.
+// Lists are comma separated.
+func Env(e notifier.Event) []string {
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{"PLAN_CODE", e.PlanCode},
+		{"INVOICE_NAME", e.InvoiceName},
+		{"MEMORY", e.Memory},
+		{"STORAGE", e.Storage},
+		{"DATACENTERS", strings.Join(e.DatacenterCodes, ",")},
+		{"DATACENTER_NAMES", strings.Join(e.Datacenters, ",")},
+		{"PRICE", strconv.FormatFloat(e.Price, 'f', 2, 64)},
+		{"CURRENCY", e.Currency},
+		{"SUBSIDIARY", e.Subsidiary},
+	}
+
+	env := make([]string, 0, len(vars))
+	for _, v := range vars {
+		env = append(env, EnvVarPrefix+v.name+"="+v.value)
+	}
+
+	return env
+}
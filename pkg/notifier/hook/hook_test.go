@@ -0,0 +1,94 @@
+package hook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+var event = notifier.Event{
+	PlanCode:        "24ska01",
+	InvoiceName:     "KS-A | Intel i7-6700k",
+	Memory:          "ram-32g-noecc-2133",
+	Datacenters:     []string{"Gravelines (France)", "Roubaix (France)"},
+	DatacenterCodes: []string{"gra", "rbx"},
+	Price:           4.99,
+	Currency:        "EUR",
+}
+
+func TestSend(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output")
+
+	logger, hook := test.NewNullLogger()
+	n := New(`cat > ` + output + `; echo "$KIMSUFI_PLAN_CODE $KIMSUFI_DATACENTERS $KIMSUFI_PRICE" >> ` + output + `; echo warning >&2`)
+	n.Logger = logger
+
+	err := n.Send(context.Background(), event)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], `{"planCode":"24ska01",`) {
+		t.Errorf("expected JSON event on stdin, got %q", lines[0])
+	}
+	if lines[1] != "24ska01 gra,rbx 4.99" {
+		t.Errorf("unexpected environment variables %q", lines[1])
+	}
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != log.WarnLevel || entry.Message != "on-available: warning" {
+		t.Errorf("expected stderr to be logged, got %+v", entry)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		timeout  time.Duration
+		expected string
+	}{
+		{
+			name:     "exit code",
+			command:  "exit 3",
+			expected: "command failed: exit status 3",
+		},
+		{
+			name:     "timeout",
+			command:  "sleep 5",
+			timeout:  50 * time.Millisecond,
+			expected: "command timed out after 50ms",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := New(tc.command)
+			if tc.timeout > 0 {
+				n.Timeout = tc.timeout
+			}
+
+			err := n.Send(context.Background(), event)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// PostJSON posts payload encoded as JSON to url.
+// It returns an error when the response status code is not 2xx.
+// client is optional, http.DefaultClient is used when nil.
+func PostJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	return PostJSONWithHeaders(ctx, client, url, nil, payload)
+}
+
+// PostJSONWithHeaders is like PostJSON, and sets the given headers on the request,
+// e.g. to authenticate.
+func PostJSONWithHeaders(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, b)
+	}
+
+	return nil
+}
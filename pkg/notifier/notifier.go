@@ -0,0 +1,26 @@
+package notifier
+
+import (
+	"context"
+)
+
+// Notifier sends availability events to a notification sink.
+type Notifier interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// BatchNotifier is a Notifier able to send multiple events at once,
+// e.g. to group all available servers into a single message.
+type BatchNotifier interface {
+	Notifier
+
+	SendBatch(ctx context.Context, events []Event) error
+}
+
+// Func is an adapter to allow the use of ordinary functions as Notifier.
+type Func func(ctx context.Context, e Event) error
+
+// Send calls f(ctx, e).
+func (f Func) Send(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
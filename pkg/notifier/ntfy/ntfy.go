@@ -0,0 +1,107 @@
+package ntfy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// BaseURLDefault is the public ntfy server URL.
+	BaseURLDefault = "https://ntfy.sh"
+	// PriorityDefault is the ntfy default priority.
+	PriorityDefault = 3
+)
+
+// Priorities maps the ntfy priority names to their value.
+// see https://docs.ntfy.sh/publish/#message-priority
+var Priorities = map[string]int{
+	"min":     1,
+	"low":     2,
+	"default": 3,
+	"high":    4,
+	"urgent":  5,
+	"max":     5,
+}
+
+// Notifier publishes events to a ntfy topic.
+// see https://docs.ntfy.sh/publish/#publish-as-json
+type Notifier struct {
+	// BaseURL is the ntfy server URL, defaults to BaseURLDefault.
+	BaseURL string
+	Topic   string
+	// Token is an optional access token, for protected topics.
+	Token    string
+	Priority int
+	Tags     []string
+	Client   *http.Client
+}
+
+// Payload represents a ntfy JSON message.
+type Payload struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Click is the URL opened when the notification is clicked.
+	Click string `json:"click,omitempty"`
+}
+
+// New creates a new Notifier publishing to the given topic on the public ntfy server.
+func New(topic string) *Notifier {
+	return &Notifier{
+		BaseURL:  BaseURLDefault,
+		Topic:    topic,
+		Priority: PriorityDefault,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send publishes the event, clicking the notification opens the OVH order page.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	baseURL := n.BaseURL
+	if baseURL == "" {
+		baseURL = BaseURLDefault
+	}
+
+	var headers map[string]string
+	if n.Token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + n.Token}
+	}
+
+	return notifier.PostJSONWithHeaders(ctx, n.Client, strings.TrimSuffix(baseURL, "/"), headers, n.NewPayload(e))
+}
+
+// NewPayload returns the message for the event.
+func (n *Notifier) NewPayload(e notifier.Event) Payload {
+	title, message, _ := strings.Cut(e.FormatText(), "\n")
+
+	return Payload{
+		Topic:    n.Topic,
+		Title:    title,
+		Message:  message,
+		Priority: n.Priority,
+		Tags:     n.Tags,
+		Click:    e.OrderURL(),
+	}
+}
+
+// ParsePriority returns the priority value from its name or number, e.g. high or 4.
+func ParsePriority(s string) (int, error) {
+	if p, ok := Priorities[strings.ToLower(s)]; ok {
+		return p, nil
+	}
+
+	p, err := strconv.Atoi(s)
+	if err != nil || p < Priorities["min"] || p > Priorities["max"] {
+		return 0, fmt.Errorf("invalid ntfy priority %q, allowed values: min, low, default, high, urgent or 1 to 5", s)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,95 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+func TestSend(t *testing.T) {
+	var (
+		got           Payload
+		authorization string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&got)
+		if err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	n := New("kimsufi")
+	n.BaseURL = server.URL + "/"
+	n.Token = "tk_secret"
+	n.Priority = Priorities["high"]
+	n.Tags = []string{"computer"}
+
+	e := notifier.Event{
+		PlanCode:    "24ska01",
+		InvoiceName: "KS-A | Intel i7-6700k",
+		Datacenters: []string{"Gravelines (France)"},
+		Price:       4.99,
+		Currency:    "EUR",
+		Subsidiary:  "FR",
+	}
+
+	err := n.Send(context.Background(), e)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	want := Payload{
+		Topic:    "kimsufi",
+		Title:    "KS-A | Intel i7-6700k is available",
+		Message:  "Plan code: 24ska01\nPrice: 4.99 EUR\nDatacenters: Gravelines (France)",
+		Priority: 4,
+		Tags:     []string{"computer"},
+		Click:    "https://eco.ovhcloud.com/fr/order/express/#/express/review?products=~(~(planCode~'24ska01~productId~'eco~pricingMode~'default~quantity~1))",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("payload mismatch (-want +got):\n%s", diff)
+	}
+
+	if authorization != "Bearer tk_secret" {
+		t.Errorf("unexpected Authorization header %q", authorization)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int
+		err      bool
+	}{
+		{input: "min", expected: 1},
+		{input: "Urgent", expected: 5},
+		{input: "4", expected: 4},
+		{input: "0", err: true},
+		{input: "loud", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParsePriority(tc.input)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
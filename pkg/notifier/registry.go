@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Registry holds named notifiers and fans out events to all of them.
+type Registry struct {
+	names     []string
+	notifiers map[string]Notifier
+}
+
+// NewRegistry creates a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		notifiers: make(map[string]Notifier),
+	}
+}
+
+// Register adds a notifier under the given name.
+// It returns an error if a notifier is already registered with this name.
+func (r *Registry) Register(name string, n Notifier) error {
+	if _, found := r.notifiers[name]; found {
+		return fmt.Errorf("notifier %s already registered", name)
+	}
+
+	r.names = append(r.names, name)
+	r.notifiers[name] = n
+
+	return nil
+}
+
+// Names returns the names of the registered notifiers, in registration order.
+func (r *Registry) Names() []string {
+	return r.names
+}
+
+// Len returns the number of registered notifiers.
+func (r *Registry) Len() int {
+	return len(r.names)
+}
+
+// Send sends the event to all registered notifiers concurrently.
+// A failing notifier does not prevent the others from being notified,
+// all errors are returned joined together, each prefixed by the notifier name.
+func (r *Registry) Send(ctx context.Context, e Event) error {
+	errs := make([]error, len(r.names))
+
+	var wg sync.WaitGroup
+	for i, name := range r.names {
+		wg.Go(func() {
+			err := send(ctx, r.notifiers[name], e)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// SendAll sends all the events to all registered notifiers concurrently.
+// Notifiers implementing BatchNotifier receive all the events at once,
+// the others receive each event separately.
+// A failing notifier does not prevent the others from being notified,
+// all errors are returned joined together, each prefixed by the notifier name.
+func (r *Registry) SendAll(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(r.names))
+
+	var wg sync.WaitGroup
+	for i, name := range r.names {
+		wg.Go(func() {
+			err := sendAll(ctx, r.notifiers[name], events)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// sendAll sends the events to a single notifier,
+// at once when it implements BatchNotifier, or one by one otherwise.
+func sendAll(ctx context.Context, n Notifier, events []Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if b, ok := n.(BatchNotifier); ok {
+		return b.SendBatch(ctx, events)
+	}
+
+	var errs []error
+	for _, e := range events {
+		err := n.Send(ctx, e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.PlanCode, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send sends the event to a single notifier,
+// recovering from a panic so it does not affect the other notifiers.
+func send(ctx context.Context, n Notifier, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return n.Send(ctx, e)
+}
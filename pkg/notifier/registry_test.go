@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	noop := Func(func(context.Context, Event) error { return nil })
+
+	err := r.Register("first", noop)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	err = r.Register("first", noop)
+	if err == nil {
+		t.Error("expected error registering the same name twice")
+	}
+
+	err = r.Register("second", noop)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second"}, r.Names()); diff != "" {
+		t.Errorf("Names() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegistrySend(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	record := func(name string) Notifier {
+		return Func(func(_ context.Context, e Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, name+":"+e.PlanCode)
+			return nil
+		})
+	}
+
+	r := NewRegistry()
+	_ = r.Register("ok1", record("ok1"))
+	_ = r.Register("failing", Func(func(context.Context, Event) error {
+		return errors.New("boom")
+	}))
+	_ = r.Register("panicking", Func(func(context.Context, Event) error {
+		panic("oops")
+	}))
+	_ = r.Register("ok2", record("ok2"))
+
+	err := r.Send(context.Background(), Event{PlanCode: "24ska01"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	want := "failing: boom\npanicking: panic: oops"
+	if err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err.Error())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 {
+		t.Errorf("expected 2 notifications, got %v", received)
+	}
+}
+
+// batchRecorder records the number of events received by each method.
+type batchRecorder struct {
+	single int
+	batch  []int
+}
+
+func (b *batchRecorder) Send(context.Context, Event) error {
+	b.single++
+	return nil
+}
+
+func (b *batchRecorder) SendBatch(_ context.Context, events []Event) error {
+	b.batch = append(b.batch, len(events))
+	return nil
+}
+
+func TestRegistrySendAll(t *testing.T) {
+	var single int
+	batch := &batchRecorder{}
+
+	r := NewRegistry()
+	_ = r.Register("single", Func(func(context.Context, Event) error {
+		single++
+		return nil
+	}))
+	_ = r.Register("batch", batch)
+
+	events := []Event{{PlanCode: "24ska01"}, {PlanCode: "24sk10"}, {PlanCode: "24sk20"}}
+	err := r.SendAll(context.Background(), events)
+	if err != nil {
+		t.Fatalf("SendAll failed: %v", err)
+	}
+
+	if single != 3 {
+		t.Errorf("expected 3 single events, got %d", single)
+	}
+	if batch.single != 0 || len(batch.batch) != 1 || batch.batch[0] != 3 {
+		t.Errorf("expected a single batch of 3 events, got %+v", batch)
+	}
+}
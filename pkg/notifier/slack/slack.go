@@ -0,0 +1,102 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+// Notifier sends events to a Slack channel using an incoming webhook.
+// see https://api.slack.com/messaging/webhooks
+type Notifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Payload represents a Slack message.
+type Payload struct {
+	// Text is the fallback text used in notifications.
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks"`
+}
+
+// Block represents a Slack layout block.
+// see https://api.slack.com/reference/block-kit/blocks
+type Block struct {
+	Type   string `json:"type"`
+	Text   *Text  `json:"text,omitempty"`
+	Fields []Text `json:"fields,omitempty"`
+}
+
+// Text represents a Slack text object.
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// New creates a new Notifier posting to the given webhook URL.
+func New(webhookURL string) *Notifier {
+	return &Notifier{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send sends the event as a message with blocks.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	return notifier.PostJSON(ctx, n.Client, n.WebhookURL, NewPayload(e))
+}
+
+// NewPayload returns the webhook message for the event.
+func NewPayload(e notifier.Event) Payload {
+	var fields []Text
+	addField := func(name, value string) {
+		if value == "" {
+			return
+		}
+		fields = append(fields, Text{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", name, escape(value))})
+	}
+	addField("CPU", e.Specs.CPU)
+	addField("Memory", e.Specs.Memory)
+	addField("Storage", strings.Join(e.Specs.Storage, "\n"))
+	addField("Price", e.FormatPrice())
+
+	blocks := []Block{
+		{
+			Type: "header",
+			Text: &Text{Type: "plain_text", Text: e.InvoiceName},
+		},
+		{
+			Type: "section",
+			Text: &Text{Type: "mrkdwn", Text: fmt.Sprintf("`%s` is available", escape(e.PlanCode))},
+		},
+	}
+
+	if len(fields) > 0 {
+		blocks = append(blocks, Block{Type: "section", Fields: fields})
+	}
+
+	var datacenters []string
+	for _, d := range e.Datacenters {
+		datacenters = append(datacenters, "• "+escape(d))
+	}
+	blocks = append(blocks, Block{
+		Type: "section",
+		Text: &Text{Type: "mrkdwn", Text: "*Datacenters*\n" + strings.Join(datacenters, "\n")},
+	})
+
+	return Payload{
+		Text:   fmt.Sprintf("%s is available in %s", e.InvoiceName, strings.Join(e.Datacenters, ", ")),
+		Blocks: blocks,
+	}
+}
+
+// escape escapes the control characters of Slack mrkdwn.
+// see https://api.slack.com/reference/surfaces/formatting#escaping
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
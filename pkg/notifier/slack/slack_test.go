@@ -0,0 +1,61 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+func TestNewPayload(t *testing.T) {
+	e := notifier.Event{
+		PlanCode:    "24ska01",
+		InvoiceName: "KS-A | Intel i7-6700k",
+		Datacenters: []string{"Gravelines (France)"},
+		Price:       4.99,
+		Currency:    "EUR",
+		Specs: notifier.Specs{
+			Memory: "32 Go DDR4",
+		},
+	}
+
+	want := Payload{
+		Text: "KS-A | Intel i7-6700k is available in Gravelines (France)",
+		Blocks: []Block{
+			{Type: "header", Text: &Text{Type: "plain_text", Text: "KS-A | Intel i7-6700k"}},
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: "`24ska01` is available"}},
+			{Type: "section", Fields: []Text{
+				{Type: "mrkdwn", Text: "*Memory*\n32 Go DDR4"},
+				{Type: "mrkdwn", Text: "*Price*\n4.99 EUR"},
+			}},
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: "*Datacenters*\n• Gravelines (France)"}},
+		},
+	}
+
+	got := NewPayload(e)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewPayload() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service")) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	err := New(server.URL).Send(context.Background(), notifier.Event{PlanCode: "24ska01"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expected := "unexpected status code 404: no_service"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+}
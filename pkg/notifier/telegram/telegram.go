@@ -0,0 +1,242 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// BaseURLDefault is the Telegram Bot API base URL.
+	BaseURLDefault = "https://api.telegram.org"
+	// MaxRetriesDefault is the number of times a rate limited message is retried.
+	MaxRetriesDefault = 3
+
+	parseMode = "MarkdownV2"
+)
+
+// markdownV2Replacer escapes the characters which must be escaped in MarkdownV2.
+// see https://core.telegram.org/bots/api#markdownv2-style
+var markdownV2Replacer = strings.NewReplacer(
+	`\`, `\\`,
+	`_`, `\_`,
+	`*`, `\*`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`~`, `\~`,
+	"`", "\\`",
+	`>`, `\>`,
+	`#`, `\#`,
+	`+`, `\+`,
+	`-`, `\-`,
+	`=`, `\=`,
+	`|`, `\|`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`.`, `\.`,
+	`!`, `\!`,
+)
+
+// Notifier sends events as messages to a Telegram chat using the Bot API.
+type Notifier struct {
+	// BaseURL is the Bot API base URL, defaults to BaseURLDefault.
+	BaseURL string
+	Token   string
+	ChatID  string
+	// MaxRetries is the number of times a rate limited message is retried.
+	MaxRetries int
+	Client     *http.Client
+
+	// wait is used to wait before retrying a rate limited message.
+	wait func(ctx context.Context, d time.Duration) error
+}
+
+// sendMessageRequest represents the request to send a message.
+// see https://core.telegram.org/bots/api#sendmessage
+type sendMessageRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// response represents a Bot API response.
+// see https://core.telegram.org/bots/api#making-requests
+type response struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	ErrorCode   int    `json:"error_code"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// New creates a new Notifier sending messages to chatID using the bot token.
+func New(token, chatID string) *Notifier {
+	return &Notifier{
+		BaseURL:    BaseURLDefault,
+		Token:      token,
+		ChatID:     chatID,
+		MaxRetries: MaxRetriesDefault,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+		wait:       wait,
+	}
+}
+
+// Send sends the event as a MarkdownV2 message.
+// When rate limited, the message is retried after the delay requested by Telegram, up to MaxRetries times.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	body, err := json.Marshal(sendMessageRequest{
+		ChatID:                n.ChatID,
+		Text:                  FormatMessage(e),
+		ParseMode:             parseMode,
+		DisableWebPagePreview: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := n.sendMessage(ctx, body)
+		if err != nil {
+			return err
+		}
+
+		if resp.OK {
+			return nil
+		}
+
+		if resp.ErrorCode != http.StatusTooManyRequests || attempt >= n.MaxRetries {
+			return fmt.Errorf("telegram error (code %d): %s", resp.ErrorCode, resp.Description)
+		}
+
+		waitFunc := n.wait
+		if waitFunc == nil {
+			waitFunc = wait
+		}
+
+		err = waitFunc(ctx, time.Duration(resp.Parameters.RetryAfter)*time.Second)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sendMessage performs a single sendMessage API request.
+func (n *Notifier) sendMessage(ctx context.Context, body []byte) (*response, error) {
+	baseURL := n.BaseURL
+	if baseURL == "" {
+		baseURL = BaseURLDefault
+	}
+	u := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(baseURL, "/"), n.Token)
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		// Do not leak the bot token which is part of the URL.
+		return nil, fmt.Errorf("telegram request failed: %w", redactToken(err, n.Token))
+	}
+	defer httpResp.Body.Close() // nolint:errcheck
+
+	var resp response
+	err = json.NewDecoder(httpResp.Body).Decode(&resp)
+	if err != nil {
+		return nil, fmt.Errorf("telegram response (status %s): %w", httpResp.Status, err)
+	}
+
+	return &resp, nil
+}
+
+// FormatMessage returns the MarkdownV2 message for the event.
+func FormatMessage(e notifier.Event) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%s* is available\n", EscapeMarkdownV2(e.InvoiceName))
+	fmt.Fprintf(&b, "Plan code: `%s`\n", escapeCode(e.PlanCode))
+	if e.Memory != "" {
+		fmt.Fprintf(&b, "Memory: %s\n", EscapeMarkdownV2(e.Memory))
+	}
+	if e.Storage != "" {
+		fmt.Fprintf(&b, "Storage: %s\n", EscapeMarkdownV2(e.Storage))
+	}
+	if price := e.FormatPrice(); price != "" {
+		fmt.Fprintf(&b, "Price: %s\n", EscapeMarkdownV2(price))
+	}
+	fmt.Fprintf(&b, "Datacenters: %s", EscapeMarkdownV2(strings.Join(e.Datacenters, ", ")))
+
+	return b.String()
+}
+
+// EscapeMarkdownV2 escapes s to be used as text in a MarkdownV2 message.
+// e.g. KS-A | Intel i7-6700k -> KS\-A \| Intel i7\-6700k
+func EscapeMarkdownV2(s string) string {
+	return markdownV2Replacer.Replace(s)
+}
+
+// escapeCode escapes s to be used inside a MarkdownV2 code entity.
+func escapeCode(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(s)
+}
+
+// redactedError hides the bot token from the message of the wrapped error,
+// the error chain is kept for errors.Is and errors.As.
+type redactedError struct {
+	err   error
+	token string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.token, "<token>")
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactToken replaces the bot token in the error message,
+// and in the URL of the wrapped *url.Error.
+func redactToken(err error, token string) error {
+	if token == "" {
+		return err
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = strings.ReplaceAll(urlErr.URL, token, "<token>")
+	}
+
+	return &redactedError{err: err, token: token}
+}
+
+// wait waits for the given duration or until the context is done.
+func wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
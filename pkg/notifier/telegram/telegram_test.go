@@ -0,0 +1,152 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "KS-A | Intel i7-6700k", expected: `KS\-A \| Intel i7\-6700k`},
+		{input: "4.99 EUR", expected: `4\.99 EUR`},
+		{input: "Gravelines (France)", expected: `Gravelines \(France\)`},
+		{input: `a\b_c*d`, expected: `a\\b\_c\*d`},
+		{input: "plain", expected: "plain"},
+	}
+
+	for _, tc := range testCases {
+		actual := EscapeMarkdownV2(tc.input)
+		if actual != tc.expected {
+			t.Errorf("EscapeMarkdownV2(%q): expected %q, got %q", tc.input, tc.expected, actual)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var requests []sendMessageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botsecret/sendMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		var req sendMessageRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		// Rate limit the first request
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`)) // nolint:errcheck
+			return
+		}
+
+		w.Write([]byte(`{"ok":true}`)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	var waited []time.Duration
+	n := New("secret", "1234")
+	n.BaseURL = server.URL
+	n.wait = func(_ context.Context, d time.Duration) error {
+		waited = append(waited, d)
+		return nil
+	}
+
+	e := notifier.Event{
+		PlanCode:    "24ska01",
+		InvoiceName: "KS-A | Intel i7-6700k",
+		Datacenters: []string{"Gravelines (France)"},
+		Price:       4.99,
+		Currency:    "EUR",
+	}
+
+	err := n.Send(context.Background(), e)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if len(waited) != 1 || waited[0] != 7*time.Second {
+		t.Errorf("expected to wait 7s once, got %v", waited)
+	}
+
+	req := requests[1]
+	if req.ChatID != "1234" || req.ParseMode != parseMode {
+		t.Errorf("unexpected request %+v", req)
+	}
+	expected := "*KS\\-A \\| Intel i7\\-6700k* is available\nPlan code: `24ska01`\nPrice: 4\\.99 EUR\nDatacenters: Gravelines \\(France\\)"
+	if req.Text != expected {
+		t.Errorf("expected text %q, got %q", expected, req.Text)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	n := New("secret", "1234")
+	n.BaseURL = server.URL
+
+	err := n.Send(context.Background(), notifier.Event{PlanCode: "24ska01"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expected := "telegram error (code 400): Bad Request: chat not found"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestSendRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	n := New("secret", "1234")
+	n.BaseURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := n.Send(ctx, notifier.Event{PlanCode: "24ska01"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected the token to be redacted, got %q", err.Error())
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Fatalf("expected error to wrap a *url.Error, got %v", err)
+	}
+	if strings.Contains(urlErr.URL, "secret") {
+		t.Errorf("expected the token to be redacted from the URL, got %q", urlErr.URL)
+	}
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+const (
+	// TemplateDefault renders the event as JSON.
+	TemplateDefault = `{{ json . }}`
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of the body.
+	// Its value is formatted as sha256=<hex encoded signature>.
+	SignatureHeader = "X-Kimsufi-Signature"
+
+	TimeoutDefault    = 10 * time.Second
+	RetriesDefault    = 3
+	RetryDelayDefault = time.Second
+)
+
+// Notifier posts events to a webhook URL, the JSON body is rendered from a template.
+type Notifier struct {
+	URL      string
+	Template *template.Template
+	Headers  map[string]string
+	// Secret is used to sign the body, no signature is sent when empty.
+	Secret string
+	// Retries is the number of times a failed request is retried.
+	Retries int
+	// RetryDelay is the delay before the first retry, it grows linearly with each retry.
+	RetryDelay time.Duration
+	Client     *http.Client
+}
+
+// statusError represents a webhook response with an unexpected status code.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.code, e.body)
+}
+
+// New creates a new Notifier posting to url, using the default template.
+func New(url string) *Notifier {
+	return &Notifier{
+		URL:        url,
+		Template:   template.Must(NewTemplate(TemplateDefault)),
+		Retries:    RetriesDefault,
+		RetryDelay: RetryDelayDefault,
+		Client:     &http.Client{Timeout: TimeoutDefault},
+	}
+}
+
+// NewTemplate parses text as a body template.
+// The template is executed over a notifier.Event, and provides the following functions:
+// - json: encodes a value as JSON, e.g. {{ json .InvoiceName }}
+// - join: joins a list of strings, e.g. {{ join .Datacenters ", " }}
+func NewTemplate(text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"join": strings.Join,
+	}
+
+	return template.New("webhook").Funcs(funcs).Parse(text)
+}
+
+// Render renders the body for the event, it ensures the body is valid JSON.
+func (n *Notifier) Render(e notifier.Event) ([]byte, error) {
+	var body bytes.Buffer
+	err := n.Template.Execute(&body, e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	if !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("rendered template is not valid JSON: %s", body.String())
+	}
+
+	return body.Bytes(), nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of body using secret.
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) // nolint:errcheck
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the event to the webhook URL.
+// Network errors, 429 and 5xx responses are retried up to Retries times.
+func (n *Notifier) Send(ctx context.Context, e notifier.Event) error {
+	body, err := n.Render(e)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil || !isRetryable(err) || attempt >= n.Retries {
+			return err
+		}
+
+		t := time.NewTimer(n.RetryDelay * time.Duration(attempt+1))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// post performs a single webhook request.
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.Headers {
+		req.Header.Set(key, value)
+	}
+	if n.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(body, n.Secret))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return statusError{code: resp.StatusCode, body: string(b)}
+	}
+
+	return nil
+}
+
+// isRetryable returns true if the request should be retried.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var e statusError
+	if errors.As(err, &e) {
+		return e.code == http.StatusTooManyRequests || e.code >= http.StatusInternalServerError
+	}
+
+	return true
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/notifier"
+)
+
+var testEvent = notifier.Event{
+	PlanCode:        "24ska01",
+	InvoiceName:     "KS-A | Intel i7-6700k",
+	Datacenters:     []string{"Gravelines (France)", "Roubaix (France)"},
+	DatacenterCodes: []string{"gra", "rbx"},
+	Price:           4.99,
+	Currency:        "EUR",
+	Subsidiary:      "FR",
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name          string
+		template      string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "default template",
+			template: TemplateDefault,
+			expected: `{"planCode":"24ska01","invoiceName":"KS-A | Intel i7-6700k","datacenters":["Gravelines (France)","Roubaix (France)"],"datacenterCodes":["gra","rbx"],"price":4.99,"currency":"EUR","subsidiary":"FR","specs":{}}`,
+		},
+		{
+			name:     "custom template",
+			template: `{"text": {{ json (printf "%s available in %s for %.2f %s" .InvoiceName (join .DatacenterCodes ", ") .Price .Currency) }}}`,
+			expected: `{"text": "KS-A | Intel i7-6700k available in gra, rbx for 4.99 EUR"}`,
+		},
+		{
+			name:          "invalid JSON",
+			template:      `{"text": {{ .InvoiceName }}}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := New("http://localhost")
+			n.Template = mustTemplate(t, tc.template)
+
+			body, err := n.Render(testEvent)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("expected error, got %s", body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+
+			if string(body) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, body)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("expected custom header, got %v", r.Header)
+		}
+		if r.Header.Get(SignatureHeader) != "sha256="+Sign(body, "secret") {
+			t.Errorf("invalid signature %s", r.Header.Get(SignatureHeader))
+		}
+		if string(body) != `{"plan":"24ska01"}` {
+			t.Errorf("unexpected body %s", body)
+		}
+	}))
+	defer server.Close()
+
+	n := New(server.URL)
+	n.Template = mustTemplate(t, `{"plan":{{ json .PlanCode }}}`)
+	n.Headers = map[string]string{"Authorization": "Bearer token"}
+	n.Secret = "secret"
+	n.RetryDelay = time.Millisecond
+
+	err := n.Send(context.Background(), testEvent)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestSendNotRetryable(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	n := New(server.URL)
+	n.RetryDelay = time.Millisecond
+
+	err := n.Send(context.Background(), testEvent)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac secret
+	expected := "77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13"
+	actual := Sign([]byte("{}"), "secret")
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func mustTemplate(t *testing.T, text string) *template.Template {
+	t.Helper()
+
+	tmpl, err := NewTemplate(text)
+	if err != nil {
+		t.Fatalf("NewTemplate failed: %v", err)
+	}
+
+	return tmpl
+}
@@ -0,0 +1,168 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is an output format.
+type Format string
+
+const (
+	// FormatTable displays a human readable table, rendered by each command.
+	FormatTable Format = "table"
+	// FormatJSON encodes all rows as a single JSON array.
+	FormatJSON Format = "json"
+	// FormatJSONL encodes each row as a JSON object on its own line.
+	FormatJSONL Format = "jsonl"
+	// FormatYAML encodes all rows as a single YAML sequence.
+	FormatYAML Format = "yaml"
+	// FormatCSV encodes rows as CSV records, with a header record using the JSON field names.
+	FormatCSV Format = "csv"
+	// FormatTemplate renders each row using a Go template, see Printer.
+	FormatTemplate Format = "template"
+)
+
+// Formats is the list of supported output formats.
+var Formats = []Format{FormatTable, FormatJSON, FormatJSONL, FormatYAML, FormatCSV, FormatTemplate}
+
+// FormatNames returns the names of the supported output formats.
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		names = append(names, string(f))
+	}
+
+	return names
+}
+
+// ParseFormat returns the Format matching s.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid output format %q (allowed values: %s)", s, strings.Join(FormatNames(), ", "))
+}
+
+// Write encodes rows to w using the format.
+// rows must be a slice of structs, their fields are named after their json tag.
+// FormatTable is not supported as tables are specific to each command,
+// and FormatTemplate requires a template, see WriteTemplate.
+func Write(w io.Writer, format Format, rows any) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+
+	// Always encode an empty list instead of null.
+	if v.IsNil() {
+		rows = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+
+	switch format {
+	case FormatJSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(rows)
+	case FormatJSONL:
+		e := json.NewEncoder(w)
+		for i := range v.Len() {
+			err := e.Encode(v.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatYAML:
+		e := yaml.NewEncoder(w)
+		e.SetIndent(2)
+		err := e.Encode(rows)
+		if err != nil {
+			return err
+		}
+		return e.Close()
+	case FormatCSV:
+		return writeCSV(w, v)
+	}
+
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+// writeCSV writes the rows as CSV records.
+// Slices values are joined with a comma.
+func writeCSV(w io.Writer, rows reflect.Value) error {
+	t := rows.Type().Elem()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("rows must be a slice of structs, got %s", rows.Type())
+	}
+
+	c := csv.NewWriter(w)
+
+	var header []string
+	for i := range t.NumField() {
+		header = append(header, fieldName(t.Field(i)))
+	}
+
+	err := c.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows.Len() {
+		row := rows.Index(i)
+
+		var record []string
+		for j := range row.NumField() {
+			record = append(record, formatValue(row.Field(j)))
+		}
+
+		err = c.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+	return c.Error()
+}
+
+// fieldName returns the json tag name of the field, or its name when not set.
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+
+	return name
+}
+
+// formatValue returns the CSV representation of v.
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		var values []string
+		for i := range v.Len() {
+			values = append(values, formatValue(v.Index(i)))
+		}
+		return strings.Join(values, ",")
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	}
+
+	return fmt.Sprint(v.Interface())
+}
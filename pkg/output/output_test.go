@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type row struct {
+	PlanCode    string   `json:"planCode" yaml:"planCode"`
+	Price       float64  `json:"price" yaml:"price"`
+	Datacenters []string `json:"datacenters" yaml:"datacenters"`
+}
+
+func TestWrite(t *testing.T) {
+	rows := []row{
+		{PlanCode: "24ska01", Price: 4.99, Datacenters: []string{"gra", "rbx"}},
+		{PlanCode: "24sk10", Price: 17},
+	}
+
+	testCases := []struct {
+		format   Format
+		rows     any
+		expected string
+	}{
+		{
+			format: FormatJSON,
+			rows:   rows,
+			expected: `[
+  {
+    "planCode": "24ska01",
+    "price": 4.99,
+    "datacenters": [
+      "gra",
+      "rbx"
+    ]
+  },
+  {
+    "planCode": "24sk10",
+    "price": 17,
+    "datacenters": null
+  }
+]
+`,
+		},
+		{
+			format: FormatJSONL,
+			rows:   rows,
+			expected: `{"planCode":"24ska01","price":4.99,"datacenters":["gra","rbx"]}
+{"planCode":"24sk10","price":17,"datacenters":null}
+`,
+		},
+		{
+			format: FormatYAML,
+			rows:   rows,
+			expected: `- planCode: 24ska01
+  price: 4.99
+  datacenters:
+    - gra
+    - rbx
+- planCode: 24sk10
+  price: 17
+  datacenters: []
+`,
+		},
+		{
+			format: FormatCSV,
+			rows:   rows,
+			expected: `planCode,price,datacenters
+24ska01,4.99,"gra,rbx"
+24sk10,17,
+`,
+		},
+		{
+			format:   FormatJSON,
+			rows:     []row(nil),
+			expected: "[]\n",
+		},
+		{
+			format:   FormatCSV,
+			rows:     []row(nil),
+			expected: "planCode,price,datacenters\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var b bytes.Buffer
+			err := Write(&b, tc.format, tc.rows)
+			if err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+
+			if b.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("jsonl")
+	if err != nil || f != FormatJSONL {
+		t.Errorf("expected jsonl, got %q, %v", f, err)
+	}
+
+	_, err = ParseFormat("xml")
+	if err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
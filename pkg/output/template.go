@@ -0,0 +1,126 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi"
+	kimsufiavailability "github.com/TheoBrigitte/kimsufi-notifier/pkg/kimsufi/availability"
+)
+
+// templatePrefix is the prefix of the output value holding a template, e.g. template={{ .PlanCode }}.
+const templatePrefix = string(FormatTemplate) + "="
+
+// TemplateFuncs are the helper functions available in templates.
+var TemplateFuncs = template.FuncMap{
+	// datacenterName returns the datacenter full name, or its code when unknown.
+	// e.g. gra -> Gravelines (France)
+	"datacenterName": func(code string) string {
+		d := kimsufiavailability.Datacenter{Datacenter: code}
+		if name := d.GetFullName(); name != nil {
+			return *name
+		}
+		return code
+	},
+	// price returns the price with two decimals followed by the currency.
+	// e.g. 4.99 EUR
+	"price": func(price float64, currency string) string {
+		return strings.TrimSpace(fmt.Sprintf("%.2f %s", price, currency))
+	},
+	// addonGenericName returns the generic name of an addon.
+	// e.g. ram-64g-ecc-2400-24sk50 -> ram-64g-ecc-2400
+	"addonGenericName": kimsufi.AddonGenericName,
+	// join joins a list of strings with a separator.
+	// e.g. {{ join .Datacenters ", " }}
+	"join": strings.Join,
+}
+
+// Printer writes rows in a given format.
+type Printer struct {
+	Format Format
+	// Template is used by FormatTemplate, it is executed for each row.
+	Template *template.Template
+}
+
+// NewPrinter creates a new Printer from the output and template file flags values.
+// output is either a format name or template=<template>,
+// templateFile is optional, when set its content is used as template and output is ignored.
+func NewPrinter(output, templateFile string) (*Printer, error) {
+	if templateFile != "" {
+		b, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+
+		return newTemplatePrinter(string(b))
+	}
+
+	if text, ok := strings.CutPrefix(output, templatePrefix); ok {
+		return newTemplatePrinter(text)
+	}
+
+	format, err := ParseFormat(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatTemplate {
+		return nil, fmt.Errorf("template output requires a template, use %s<template> or a template file", templatePrefix)
+	}
+
+	return &Printer{Format: format}, nil
+}
+
+// newTemplatePrinter creates a new Printer using text as template.
+func newTemplatePrinter(text string) (*Printer, error) {
+	t, err := template.New("output").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	return &Printer{Format: FormatTemplate, Template: t}, nil
+}
+
+// Print writes the rows to w.
+func (p *Printer) Print(w io.Writer, rows any) error {
+	if p.Format != FormatTemplate {
+		return Write(w, p.Format, rows)
+	}
+
+	return WriteTemplate(w, p.Template, rows)
+}
+
+// WriteTemplate executes the template for each row,
+// a new line is added after each row unless the template already ends with one.
+func WriteTemplate(w io.Writer, t *template.Template, rows any) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+
+	var b bytes.Buffer
+	for i := range v.Len() {
+		b.Reset()
+
+		err := t.Execute(&b, v.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+
+		_, err = w.Write(b.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrinterTemplate(t *testing.T) {
+	rows := []struct {
+		PlanCode    string
+		Memory      string
+		Price       float64
+		Currency    string
+		Datacenters []string
+	}{
+		{PlanCode: "24ska01", Memory: "ram-32g-noecc-2133-24ska01", Price: 4.99, Currency: "EUR", Datacenters: []string{"gra", "rbx"}},
+		{PlanCode: "24sk10", Price: 17, Currency: "EUR"},
+	}
+
+	templateFile := filepath.Join(t.TempDir(), "template")
+	err := os.WriteFile(templateFile, []byte("{{ .PlanCode }};{{ price .Price .Currency }}\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name         string
+		output       string
+		templateFile string
+		expected     string
+	}{
+		{
+			name:     "helpers",
+			output:   `template={{ .PlanCode }} {{ addonGenericName .Memory }} {{ price .Price .Currency }} {{ range .Datacenters }}{{ datacenterName . }},{{ end }}`,
+			expected: "24ska01 ram-32g-noecc-2133 4.99 EUR Gravelines (France),Roubaix (France),\n24sk10  17.00 EUR \n",
+		},
+		{
+			name:     "join",
+			output:   `template={{ join .Datacenters "|" }}`,
+			expected: "gra|rbx\n\n",
+		},
+		{
+			name:         "template file",
+			output:       "json",
+			templateFile: templateFile,
+			expected:     "24ska01;4.99 EUR\n24sk10;17.00 EUR\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPrinter(tc.output, tc.templateFile)
+			if err != nil {
+				t.Fatalf("NewPrinter failed: %v", err)
+			}
+
+			var b bytes.Buffer
+			err = p.Print(&b, rows)
+			if err != nil {
+				t.Fatalf("Print failed: %v", err)
+			}
+
+			if b.String() != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestNewPrinterError(t *testing.T) {
+	for _, output := range []string{"template", "template={{ .PlanCode", "xml"} {
+		_, err := NewPrinter(output, "")
+		if err == nil {
+			t.Errorf("expected error for %q", output)
+		}
+	}
+}